@@ -2,6 +2,7 @@
 
 # This script fixes image upload permissions for the Docker deployment
 # Run this on your Ubuntu server
+# Not needed with STORAGE_DRIVER=s3, where uploads are kept in a bucket
 
 echo "Restoring permissions for the uploads directory..."
 
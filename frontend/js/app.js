@@ -31,11 +31,45 @@ function truncateText(text, length) {
     return text.substring(0, length).trim() + '...';
 }
 
+// Sized copies of an uploaded article image: the src of the named variant,
+// and <source>/srcset attributes so the browser picks the size (and WebP
+// when it can). Images from elsewhere have none and are used as they are.
+function imageVariant(article, name) {
+    const set = article.images;
+    const formats = set && set.variants && set.variants[name];
+    if (!formats) return article.image;
+    const fallback = Object.keys(formats).find(format => format !== 'webp');
+    return fallback ? formats[fallback].url : article.image;
+}
+
+function imageSources(article, sizes) {
+    const srcset = article.images && article.images.srcset;
+    if (!srcset) return { source: '', attrs: '' };
+    const fallback = Object.keys(srcset).find(format => format !== 'webp');
+    return {
+        source: srcset.webp ? `<source type="image/webp" srcset="${srcset.webp}" sizes="${sizes}">` : '',
+        attrs: fallback ? `srcset="${srcset[fallback]}" sizes="${sizes}"` : ''
+    };
+}
+
+// Description of an article image from the media library
+function imageAlt(article) {
+    return (article.images && article.images.alt) || article.title;
+}
+
+function imageCaption(article) {
+    const set = article.images;
+    if (!set || !(set.caption || set.credit)) return '';
+    const credit = set.credit ? ` <span class="image-credit">${set.credit}</span>` : '';
+    return `<figcaption>${set.caption || ''}${credit}</figcaption>`;
+}
+
 // Load categories
 async function loadCategories() {
     try {
         const response = await fetch(`${API_BASE}/categories`);
-        const categories = await response.json();
+        const list = await response.json();
+        const categories = list.map(cat => cat.name_bn);
 
         const categoriesDiv = document.getElementById('categories');
         categoriesDiv.innerHTML = '';
@@ -47,14 +81,28 @@ async function loadCategories() {
             const btn = document.createElement('button');
             btn.className = 'category-btn' + (cat === currentCategory ? ' active' : '');
             btn.textContent = cat;
-            btn.onclick = () => selectCategory(cat);
+            btn.onclick = serverPage ? () => openCategoryPage(list.find(c => c.name_bn === cat)) : () => selectCategory(cat);
             categoriesDiv.appendChild(btn);
         });
+
+        // Category links (?category=<slug>) open with that category selected
+        const linked = list.find(cat => cat.slug === new URLSearchParams(window.location.search).get('category'));
+        if (linked) {
+            selectCategory(linked.name_bn);
+        }
     } catch (error) {
         console.error('Error loading categories:', error);
     }
 }
 
+// Server-rendered article and category pages have their own URLs, so there
+// the category buttons and search leave for the matching page
+const serverPage = document.body.dataset.page;
+
+function openCategoryPage(category) {
+    window.location.href = category ? `/category/${encodeURIComponent(category.slug)}` : '/';
+}
+
 // Select category
 function selectCategory(category) {
     console.log('!!!!! selectCategory CALLED !!!!!');
@@ -81,6 +129,8 @@ function selectCategory(category) {
         loadFeatured();
     }
 
+    loadTrending();
+
     console.log('About to call loadArticles with currentCategory:', currentCategory);
     loadArticles();
     console.log('!!!!! selectCategory FINISHED !!!!!');
@@ -100,7 +150,8 @@ async function loadFeatured() {
 
         console.log('Fetching featured from:', url);
         const response = await fetch(url);
-        const articles = await response.json();
+        const page = await response.json();
+        const articles = page.results || [];
 
         console.log('Received featured articles:', articles.length);
         featuredDiv.innerHTML = '';
@@ -118,17 +169,21 @@ async function loadFeatured() {
             const date = formatDate(article.created);
             // Use placeholder if image is null or empty to prevent 404 errors
             const imageUrl = article.image && article.image.trim() !== ''
-                ? article.image
+                ? imageVariant(article, 'hero')
                 : 'https://images.unsplash.com/photo-1585007600263-ad12200a09a5?auto=format&fit=crop&q=80&w=800&h=450';
+            const sources = imageSources(article, '(max-width: 768px) 100vw, 50vw');
 
             // Strictly truncate title if it's too long (headline)
             const displayTitle = truncateText(article.title, 60);
 
             div.innerHTML = `
                 <div class="featured-image">
-                    <img src="${imageUrl}" 
-                         alt="${article.title}" 
-                         onerror="this.onerror=null; this.src='https://images.unsplash.com/photo-1585007600263-ad12200a09a5?auto=format&fit=crop&q=80&w=800&h=450'; this.parentElement.classList.add('broken');">
+                    <picture>
+                        ${sources.source}
+                        <img src="${imageUrl}" ${sources.attrs}
+                             alt="${imageAlt(article)}" 
+                             onerror="this.onerror=null; this.removeAttribute('srcset'); this.previousElementSibling && this.previousElementSibling.remove(); this.src='https://images.unsplash.com/photo-1585007600263-ad12200a09a5?auto=format&fit=crop&q=80&w=800&h=450'; this.closest('.featured-image').classList.add('broken');">
+                    </picture>
                 </div>
                 <h3>${displayTitle}</h3>
                 <div class="meta">
@@ -146,8 +201,67 @@ async function loadFeatured() {
     }
 }
 
-// Load articles
-async function loadArticles() {
+// Load trending articles for the current category
+async function loadTrending() {
+    const trendingList = document.getElementById('trending');
+    if (!trendingList) {
+        return;
+    }
+
+    try {
+        let url = `${API_BASE}/trending?limit=5`;
+        if (currentCategory !== 'সব') {
+            url += `&category=${encodeURIComponent(currentCategory)}`;
+        }
+
+        const response = await fetch(url);
+        const articles = response.ok ? await response.json() : [];
+
+        const section = document.querySelector('.trending-section');
+        section.style.display = articles.length ? 'block' : 'none';
+        trendingList.innerHTML = '';
+
+        articles.forEach(article => {
+            const li = document.createElement('li');
+            li.textContent = truncateText(article.title, 80);
+            li.onclick = () => openArticle(article.id);
+            trendingList.appendChild(li);
+        });
+    } catch (error) {
+        console.error('Error loading trending articles:', error);
+    }
+}
+
+// Share an article with the browser's share sheet (or copy its link) and
+// count the share for trending
+async function shareArticle(id, title, slug) {
+    const url = slug
+        ? `${window.location.origin}/news/${encodeURIComponent(slug)}`
+        : `${window.location.origin}/?article=${id}`;
+    let network = 'native';
+
+    try {
+        if (navigator.share) {
+            await navigator.share({ title, url });
+        } else {
+            await navigator.clipboard.writeText(url);
+            network = 'link';
+            alert('লিংক কপি হয়েছে');
+        }
+    } catch (error) {
+        // Share sheet dismissed
+        return;
+    }
+
+    fetch(`${API_BASE}/share-article`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ id, network })
+    }).catch(error => console.error('Error recording share:', error));
+}
+
+// Load articles; with a cursor the next page is appended (infinite scroll)
+async function loadArticles(cursor) {
     console.log('=== loadArticles called ===');
     console.log('currentCategory:', currentCategory);
     console.log('currentCategory type:', typeof currentCategory);
@@ -168,6 +282,10 @@ async function loadArticles() {
             params.append('search', searchQuery);
         }
 
+        if (cursor) {
+            params.append('cursor', cursor);
+        }
+
         if (params.toString()) {
             url += '?' + params.toString();
         }
@@ -176,8 +294,9 @@ async function loadArticles() {
         console.log('Full URL with params:', url);
 
         const response = await fetch(url);
-        const articles = await response.json();
-        console.log('Received articles:', articles.length, 'articles');
+        const page = await response.json();
+        const articles = page.results || [];
+        console.log('Received articles:', articles.length, 'of', page.total_results);
 
         // Log each article's category
         articles.forEach((article, index) => {
@@ -185,9 +304,15 @@ async function loadArticles() {
         });
 
         const articlesDiv = document.getElementById('articles');
-        articlesDiv.innerHTML = '';
+        const oldButton = document.getElementById('loadMore');
+        if (oldButton) {
+            oldButton.remove();
+        }
+        if (!cursor) {
+            articlesDiv.innerHTML = '';
+        }
 
-        if (!articles || articles.length === 0) {
+        if (!cursor && articles.length === 0) {
             articlesDiv.innerHTML = '<p>কোনো খবর নেই</p>';
             return;
         }
@@ -200,16 +325,20 @@ async function loadArticles() {
             const date = formatDate(article.created);
             // Use placeholder if image is null or empty to prevent 404 errors
             const imageUrl = article.image && article.image.trim() !== ''
-                ? article.image
+                ? imageVariant(article, 'card')
                 : 'https://images.unsplash.com/photo-1504711331083-9c895941bf81?auto=format&fit=crop&q=80&w=400&h=225';
+            const sources = imageSources(article, '(max-width: 600px) 100vw, 33vw');
             const displayTitle = truncateText(article.title, 50);
 
             div.innerHTML = `
                 <div class="article-image">
-                    <img src="${imageUrl}" 
-                         alt="${article.title}" 
-                         loading="lazy"
-                         onerror="this.onerror=null; this.src='https://images.unsplash.com/photo-1504711331083-9c895941bf81?auto=format&fit=crop&q=80&w=400&h=225'; this.parentElement.classList.add('broken');">
+                    <picture>
+                        ${sources.source}
+                        <img src="${imageUrl}" ${sources.attrs}
+                             alt="${imageAlt(article)}" 
+                             loading="lazy"
+                             onerror="this.onerror=null; this.removeAttribute('srcset'); this.previousElementSibling && this.previousElementSibling.remove(); this.src='https://images.unsplash.com/photo-1504711331083-9c895941bf81?auto=format&fit=crop&q=80&w=400&h=225'; this.closest('.article-image').classList.add('broken');">
+                    </picture>
                 </div>
                 <div class="article-content">
                     <span class="category">${article.category}</span>
@@ -225,6 +354,15 @@ async function loadArticles() {
 
             articlesDiv.appendChild(div);
         });
+
+        if (page.next_cursor) {
+            const more = document.createElement('button');
+            more.id = 'loadMore';
+            more.className = 'load-more-btn';
+            more.textContent = 'আরও খবর';
+            more.onclick = () => loadArticles(page.next_cursor);
+            articlesDiv.appendChild(more);
+        }
     } catch (error) {
         console.error('Error loading articles:', error);
     }
@@ -241,14 +379,19 @@ async function openArticle(id) {
         const detailDiv = document.getElementById('articleDetail');
         detailDiv.innerHTML = `
             <div class="article-detail">
-                ${article.image ? `<img src="${article.image}" alt="${article.title}" style="width: 100%; max-height: 400px; object-fit: cover; border-radius: 10px; margin-bottom: 20px;">` : ''}
+                ${article.image ? `<figure class="article-figure">
+                    <img src="${imageVariant(article, 'hero')}" alt="${imageAlt(article)}" style="width: 100%; max-height: 400px; object-fit: cover; border-radius: 10px;">
+                    ${imageCaption(article)}
+                </figure>` : ''}
                 <h1>${article.title}</h1>
                 <div class="detail-meta">
                     <strong>${article.category}</strong> | লেখক: ${article.author} | ${date}
                 </div>
                 <div class="content">${article.content}</div>
+                <button class="share-btn" id="shareArticle">শেয়ার করুন</button>
             </div>
         `;
+        document.getElementById('shareArticle').onclick = () => shareArticle(article.id, article.title, article.slug);
 
         const modal = document.getElementById('articleModal');
         modal.classList.add('show');
@@ -264,10 +407,30 @@ function closeModal() {
 
 // Event listeners
 document.addEventListener('DOMContentLoaded', () => {
+    if (serverPage) {
+        initServerPage();
+        return;
+    }
+
     loadCategories();
-    loadFeatured();
+
+    // Searches started on a server-rendered page arrive as ?search=
+    const searched = new URLSearchParams(window.location.search).get('search');
+    if (searched) {
+        document.getElementById('searchInput').value = searched;
+        document.querySelector('.featured-section').style.display = 'none';
+    } else {
+        loadFeatured();
+    }
+    loadTrending();
     loadArticles();
 
+    // Shared links open the article directly
+    const sharedId = new URLSearchParams(window.location.search).get('article');
+    if (sharedId) {
+        openArticle(sharedId);
+    }
+
     // Search functionality
     const searchInput = document.getElementById('searchInput');
     searchInput.addEventListener('keypress', (e) => {
@@ -298,3 +461,121 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     };
 });
+
+function initServerPage() {
+    currentCategory = document.body.dataset.category || currentCategory;
+    loadCategories();
+
+    const searchInput = document.getElementById('searchInput');
+    searchInput.addEventListener('keypress', (e) => {
+        const query = searchInput.value.trim();
+        if (e.key === 'Enter' && query) {
+            window.location.href = `/?search=${encodeURIComponent(query)}`;
+        }
+    });
+
+    if (document.body.dataset.articleId) {
+        loadLiveBlog(document.body.dataset.articleId);
+    }
+
+    document.querySelectorAll('[data-share-id]').forEach(btn => {
+        btn.onclick = () => shareArticle(Number(btn.dataset.shareId), btn.dataset.shareTitle, btn.dataset.shareSlug);
+    });
+}
+
+// Breaking news ticker, kept up to date over Server-Sent Events. The
+// browser reconnects by itself and resumes from the last event it saw.
+const breakingNews = new Map();
+
+function renderBreakingNews() {
+    const ticker = document.getElementById('breakingTicker');
+    const itemsDiv = document.getElementById('breakingItems');
+    if (!ticker || !itemsDiv) return;
+
+    const items = [...breakingNews.values()]
+        .sort((a, b) => b.priority - a.priority || new Date(b.start_at) - new Date(a.start_at));
+
+    itemsDiv.innerHTML = '';
+    items.forEach(item => {
+        const el = document.createElement(item.link ? 'a' : 'span');
+        el.className = 'breaking-item';
+        el.textContent = item.headline;
+        if (item.link) el.href = item.link;
+        itemsDiv.appendChild(el);
+    });
+    ticker.hidden = items.length === 0;
+}
+
+function watchBreakingNews() {
+    if (!window.EventSource) return;
+
+    const source = new EventSource(`${API_BASE}/breaking-news/stream`);
+    source.addEventListener('snapshot', (e) => {
+        breakingNews.clear();
+        JSON.parse(e.data).forEach(item => breakingNews.set(item.id, item));
+        renderBreakingNews();
+    });
+    source.addEventListener('add', (e) => {
+        const item = JSON.parse(e.data);
+        breakingNews.set(item.id, item);
+        renderBreakingNews();
+    });
+    source.addEventListener('remove', (e) => {
+        breakingNews.delete(JSON.parse(e.data).id);
+        renderBreakingNews();
+    });
+}
+
+document.addEventListener('DOMContentLoaded', watchBreakingNews);
+
+// Live blog under an article: entries arrive over Server-Sent Events and
+// are kept newest first, pinned ones on top
+const liveEntries = new Map();
+
+async function loadLiveBlog(articleId) {
+    const response = await fetch(`${API_BASE}/live-blogs?article_id=${articleId}`);
+    if (!response.ok || !window.EventSource) return;
+    const blog = await response.json();
+
+    const source = new EventSource(`${API_BASE}/live-blogs/${blog.id}/stream`);
+    source.addEventListener('snapshot', (e) => {
+        const snapshot = JSON.parse(e.data);
+        liveEntries.clear();
+        [...(snapshot.pinned || []), ...(snapshot.entries || [])].forEach(entry => liveEntries.set(entry.id, entry));
+        renderLiveBlog(snapshot);
+    });
+    source.addEventListener('entry', (e) => addLiveEntry(JSON.parse(e.data)));
+    source.addEventListener('update', (e) => addLiveEntry(JSON.parse(e.data)));
+    source.addEventListener('delete', (e) => {
+        liveEntries.delete(JSON.parse(e.data).id);
+        renderLiveBlog();
+    });
+    source.addEventListener('status', (e) => renderLiveBlog(JSON.parse(e.data)));
+}
+
+function addLiveEntry(entry) {
+    liveEntries.set(entry.id, entry);
+    renderLiveBlog();
+}
+
+function renderLiveBlog(blog) {
+    const section = document.getElementById('liveBlog');
+    if (blog) {
+        document.getElementById('liveBlogTitle').textContent = blog.title || 'সরাসরি';
+        section.classList.toggle('ended', blog.status === 'ended');
+    }
+
+    const entries = [...liveEntries.values()]
+        .sort((a, b) => new Date(b.created_at) - new Date(a.created_at) || b.id - a.id);
+    const render = (entry) => `
+        <article class="live-entry${entry.pinned ? ' pinned' : ''}">
+            <div class="live-entry-meta">${entry.pinned ? '📌 ' : ''}${formatDate(entry.created_at, true)} | ${entry.author}</div>
+            ${entry.image ? `<img src="${entry.image}" alt="" loading="lazy">` : ''}
+            <div class="live-entry-body">${entry.body}</div>
+        </article>
+    `;
+
+    document.getElementById('livePinned').innerHTML = entries.filter(entry => entry.pinned).map(render).join('');
+    document.getElementById('liveEntries').innerHTML = entries.filter(entry => !entry.pinned).map(render).join('');
+    section.hidden = false;
+}
@@ -1,15 +1,22 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 var (
 	IsProd              bool
+	IsDev               bool
 	AppHost             string
 	AppPort             int
+	AppURL              string
+	SiteName            string
+	TrustedProxies      []string
 	DBHost              string
 	DBUser              string
 	DBPassword          string
@@ -20,6 +27,7 @@ var (
 	JWTRefreshExp       int
 	JWTResetPasswordExp int
 	JWTVerifyEmailExp   int
+	AdminEmail          string
 	SMTPHost            string
 	SMTPPort            int
 	SMTPUsername        string
@@ -28,6 +36,40 @@ var (
 	GoogleClientID      string
 	GoogleClientSecret  string
 	RedirectURL         string
+	ViewDedupeWindow    time.Duration
+	ViewFlushInterval   time.Duration
+	TrendingHalfLife    time.Duration
+	TrendingWindow      time.Duration
+	TrendingInterval    time.Duration
+	TrendingSize        int
+	TrendingViewWeight  float64
+	TrendingComWeight   float64
+	TrendingShareWeight float64
+	StreamPollInterval  time.Duration
+	StreamRetention     time.Duration
+	TrashRetention      time.Duration
+	UploadMaxBytes      int64
+	UploadMaxWidth      int
+	UploadMaxHeight     int
+	UploadMaxPixels     int
+	UploadOrphanGrace   time.Duration
+	TusMaxBytes         int64
+	TusQuotaBytes       int64
+	TusChunkMaxBytes    int64
+	TusExpiry           time.Duration
+	WebPEncoder         string
+	StorageDriver       string
+	StorageLocalDir     string
+	StoragePublicURL    string
+	StorageSignedURLs   bool
+	StorageURLExpiry    time.Duration
+	S3Endpoint          string
+	S3Region            string
+	S3Bucket            string
+	S3AccessKey         string
+	S3SecretKey         string
+	S3Prefix            string
+	S3PathStyle         bool
 )
 
 func init() {
@@ -35,8 +77,25 @@ func init() {
 
 	// server configuration
 	IsProd = viper.GetString("APP_ENV") == "prod"
+	IsDev = viper.GetString("APP_ENV") == "development"
 	AppHost = viper.GetString("APP_HOST")
 	AppPort = viper.GetInt("APP_PORT")
+	// Public address used in feeds, sitemaps and share links
+	AppURL = strings.TrimSuffix(viper.GetString("APP_URL"), "/")
+	if AppURL == "" {
+		AppURL = fmt.Sprintf("http://%s:%d", AppHost, AppPort)
+	}
+	viper.SetDefault("SITE_NAME", "দৈনিক খবর")
+	SiteName = viper.GetString("SITE_NAME")
+	// proxies (addresses or CIDR ranges) whose X-Forwarded-For is believed,
+	// so that views, shares and limits count readers and not nginx; the
+	// default covers loopback and the private ranges Docker networks use
+	viper.SetDefault("TRUSTED_PROXIES", "127.0.0.0/8,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16")
+	for _, proxy := range strings.Split(viper.GetString("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TrustedProxies = append(TrustedProxies, proxy)
+		}
+	}
 
 	// database configuration
 	DBHost = viper.GetString("DB_HOST")
@@ -52,6 +111,10 @@ func init() {
 	JWTResetPasswordExp = viper.GetInt("JWT_RESET_PASSWORD_EXP_MINUTES")
 	JWTVerifyEmailExp = viper.GetInt("JWT_VERIFY_EMAIL_EXP_MINUTES")
 
+	// the account made admin on every start, so that a fresh deploy has
+	// someone who can publish and hand out roles to the newsroom
+	AdminEmail = strings.TrimSpace(viper.GetString("ADMIN_EMAIL"))
+
 	// SMTP configuration
 	SMTPHost = viper.GetString("SMTP_HOST")
 	SMTPPort = viper.GetInt("SMTP_PORT")
@@ -63,6 +126,98 @@ func init() {
 	GoogleClientID = viper.GetString("GOOGLE_CLIENT_ID")
 	GoogleClientSecret = viper.GetString("GOOGLE_CLIENT_SECRET")
 	RedirectURL = viper.GetString("REDIRECT_URL")
+
+	// view counting configuration
+	viper.SetDefault("VIEW_DEDUPE_MINUTES", 30)
+	viper.SetDefault("VIEW_FLUSH_SECONDS", 10)
+	ViewDedupeWindow = time.Duration(viper.GetInt("VIEW_DEDUPE_MINUTES")) * time.Minute
+	ViewFlushInterval = time.Duration(viper.GetInt("VIEW_FLUSH_SECONDS")) * time.Second
+
+	// trending configuration: each view, comment and share is weighted, then
+	// halves in value every half-life; activity older than the window is ignored
+	viper.SetDefault("TRENDING_HALF_LIFE_HOURS", 6)
+	viper.SetDefault("TRENDING_WINDOW_HOURS", 48)
+	viper.SetDefault("TRENDING_REFRESH_SECONDS", 60)
+	viper.SetDefault("TRENDING_SIZE", 20)
+	viper.SetDefault("TRENDING_VIEW_WEIGHT", 1)
+	viper.SetDefault("TRENDING_COMMENT_WEIGHT", 5)
+	viper.SetDefault("TRENDING_SHARE_WEIGHT", 10)
+	TrendingHalfLife = time.Duration(viper.GetFloat64("TRENDING_HALF_LIFE_HOURS") * float64(time.Hour))
+	TrendingWindow = time.Duration(viper.GetFloat64("TRENDING_WINDOW_HOURS") * float64(time.Hour))
+	TrendingInterval = time.Duration(viper.GetInt("TRENDING_REFRESH_SECONDS")) * time.Second
+	TrendingSize = viper.GetInt("TRENDING_SIZE")
+	TrendingViewWeight = viper.GetFloat64("TRENDING_VIEW_WEIGHT")
+	TrendingComWeight = viper.GetFloat64("TRENDING_COMMENT_WEIGHT")
+	TrendingShareWeight = viper.GetFloat64("TRENDING_SHARE_WEIGHT")
+
+	// live streams (breaking news): how often each process looks for new
+	// events, and how long readers can be away and still resume
+	viper.SetDefault("STREAM_POLL_MILLISECONDS", 1000)
+	viper.SetDefault("STREAM_RETENTION_HOURS", 24)
+	StreamPollInterval = time.Duration(viper.GetInt("STREAM_POLL_MILLISECONDS")) * time.Millisecond
+	StreamRetention = time.Duration(viper.GetInt("STREAM_RETENTION_HOURS")) * time.Hour
+
+	// deleted articles are purged for good after this many days; 0 keeps them
+	viper.SetDefault("TRASH_RETENTION_DAYS", 30)
+	TrashRetention = time.Duration(viper.GetInt("TRASH_RETENTION_DAYS")) * 24 * time.Hour
+
+	// image uploads: the largest file, and the largest picture in pixels,
+	// accepted; the pixel limits keep a small file from decoding into gigabytes
+	viper.SetDefault("UPLOAD_MAX_MB", 10)
+	viper.SetDefault("UPLOAD_MAX_WIDTH", 8000)
+	viper.SetDefault("UPLOAD_MAX_HEIGHT", 8000)
+	viper.SetDefault("UPLOAD_MAX_MEGAPIXELS", 40)
+	UploadMaxBytes = int64(viper.GetFloat64("UPLOAD_MAX_MB") * (1 << 20))
+	UploadMaxWidth = viper.GetInt("UPLOAD_MAX_WIDTH")
+	UploadMaxHeight = viper.GetInt("UPLOAD_MAX_HEIGHT")
+	UploadMaxPixels = int(viper.GetFloat64("UPLOAD_MAX_MEGAPIXELS") * 1e6)
+
+	// files under frontend/uploads that neither the media library nor any
+	// article, revision, live blog or avatar refers to are removed once they
+	// are this many hours old; 0 keeps them
+	viper.SetDefault("UPLOAD_ORPHAN_HOURS", 24)
+	UploadOrphanGrace = time.Duration(viper.GetInt("UPLOAD_ORPHAN_HOURS")) * time.Hour
+
+	// resumable (tus) uploads, for video and large files on poor connections:
+	// the largest file, how much one user may have unfinished at once, the
+	// largest chunk sent in one request, and how many hours an upload that
+	// has stopped receiving data is kept before it is abandoned
+	viper.SetDefault("TUS_MAX_MB", 2048)
+	viper.SetDefault("TUS_USER_QUOTA_MB", 4096)
+	viper.SetDefault("TUS_CHUNK_MAX_MB", 16)
+	viper.SetDefault("TUS_EXPIRY_HOURS", 24)
+	TusMaxBytes = int64(viper.GetFloat64("TUS_MAX_MB") * (1 << 20))
+	TusQuotaBytes = int64(viper.GetFloat64("TUS_USER_QUOTA_MB") * (1 << 20))
+	TusChunkMaxBytes = int64(viper.GetFloat64("TUS_CHUNK_MAX_MB") * (1 << 20))
+	TusExpiry = time.Duration(viper.GetInt("TUS_EXPIRY_HOURS")) * time.Hour
+
+	// resized copies of uploads are also made in WebP with this encoder (the
+	// cwebp tool from libwebp); without it only JPEG and PNG copies are made
+	viper.SetDefault("WEBP_ENCODER", "cwebp")
+	WebPEncoder = viper.GetString("WEBP_ENCODER")
+
+	// where uploads are kept: "local" in STORAGE_LOCAL_DIR, or "s3" in a
+	// bucket of any S3-compatible store. Either way they are linked as
+	// /uploads/<name>; with s3 that redirects to STORAGE_PUBLIC_URL/<name>
+	// (the bucket itself when empty), or with STORAGE_SIGNED_URLS to a link
+	// that expires, for private buckets
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "frontend/uploads")
+	viper.SetDefault("STORAGE_URL_EXPIRY_MINUTES", 60)
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_PATH_STYLE", true)
+	StorageDriver = viper.GetString("STORAGE_DRIVER")
+	StorageLocalDir = viper.GetString("STORAGE_LOCAL_DIR")
+	StoragePublicURL = viper.GetString("STORAGE_PUBLIC_URL")
+	StorageSignedURLs = viper.GetBool("STORAGE_SIGNED_URLS")
+	StorageURLExpiry = time.Duration(viper.GetInt("STORAGE_URL_EXPIRY_MINUTES")) * time.Minute
+	S3Endpoint = viper.GetString("S3_ENDPOINT")
+	S3Region = viper.GetString("S3_REGION")
+	S3Bucket = viper.GetString("S3_BUCKET")
+	S3AccessKey = viper.GetString("S3_ACCESS_KEY")
+	S3SecretKey = viper.GetString("S3_SECRET_KEY")
+	S3Prefix = viper.GetString("S3_PREFIX")
+	S3PathStyle = viper.GetBool("S3_PATH_STYLE")
 }
 
 func loadConfig() {
@@ -16,5 +16,14 @@ func FiberConfig() fiber.Config {
 		ErrorHandler:  utils.ErrorHandler,
 		JSONEncoder:   sonic.Marshal,
 		JSONDecoder:   sonic.Unmarshal,
+		// Behind nginx the client address comes from X-Forwarded-For, taken
+		// only from the proxies trusted to set it
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableIPValidation:      true,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          TrustedProxies,
+		// Room for the largest image upload plus the multipart framing, or
+		// the largest chunk of a resumable upload
+		BodyLimit: int(max(UploadMaxBytes+1<<20, TusChunkMaxBytes)),
 	}
 }
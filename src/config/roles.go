@@ -1,8 +1,21 @@
 package config
 
 var allRoles = map[string][]string{
-	"user":  {},
-	"admin": {"getUsers", "manageUsers"},
+	"user":     {},
+	"reporter": {"getArticles", "submitArticles"},
+	"editor": {
+		"getArticles", "submitArticles", "reviewArticles", "archiveArticles",
+		"manageTags", "manageBreakingNews", "manageLiveBlogs", "manageMedia",
+	},
+	"publisher": {
+		"getArticles", "submitArticles", "reviewArticles", "publishArticles", "archiveArticles", "manageArticles",
+		"manageCategories", "manageTags", "manageBreakingNews", "manageLiveBlogs", "manageMedia", "manageTrash",
+	},
+	"admin": {
+		"getUsers", "manageUsers",
+		"getArticles", "submitArticles", "reviewArticles", "publishArticles", "archiveArticles", "manageArticles",
+		"manageCategories", "manageTags", "manageBreakingNews", "manageLiveBlogs", "manageMedia", "manageTrash",
+	},
 }
 
 var Roles = getKeys(allRoles)
@@ -15,3 +28,25 @@ func getKeys(m map[string][]string) []string {
 	}
 	return keys
 }
+
+// HasRights reports whether role has every one of the given rights.
+func HasRights(role string, rights ...string) bool {
+	granted, ok := RoleRights[role]
+	if !ok {
+		return false
+	}
+
+	for _, right := range rights {
+		found := false
+		for _, g := range granted {
+			if g == right {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -4,10 +4,12 @@ import (
 	"app/src/model"
 	"app/src/response"
 	"app/src/service"
+	"app/src/validation"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,19 +17,21 @@ import (
 
 type ArticleController struct {
 	_ArticleService service.ArticleService
+	_ViewService    service.ViewService
+	_ImageService   service.ImageService
 }
 
-func NewArticleController(s service.ArticleService) *ArticleController {
-	return &ArticleController{_ArticleService: s}
+func NewArticleController(s service.ArticleService, v service.ViewService, i service.ImageService) *ArticleController {
+	return &ArticleController{_ArticleService: s, _ViewService: v, _ImageService: i}
 }
 
 func (a *ArticleController) CreateArticle(c *fiber.Ctx) error {
 	req := new(model.Article)
-	
+
 	// Debug: Log the raw body
 	println("=== CreateArticle Debug ===")
 	println("Raw body:", string(c.Body()))
-	
+
 	if err := c.BodyParser(req); err != nil {
 		println("Body parser error:", err.Error())
 		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
@@ -45,44 +49,70 @@ func (a *ArticleController) CreateArticle(c *fiber.Ctx) error {
 
 	req.Created = time.Now()
 
-	created, err := a._ArticleService.CreateArticle(req)
+	actor, _ := c.Locals("user").(*model.User)
+	created, err := a._ArticleService.CreateArticle(req, actor)
 	if err != nil {
 		println("CreateArticle service error:", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
-			Code:    fiber.StatusInternalServerError,
-			Status:  "error",
-			Message: "could not create article",
-			Errors:  err.Error(),
-		})
+		return serviceError(c, err, "could not create article")
 	}
 
 	println("Article created successfully with ID:", created.ID)
 	println("===========================")
-	
+
 	return c.Status(fiber.StatusCreated).JSON(created)
 }
 
 func (a *ArticleController) GetAll(c *fiber.Ctx) error {
-	category := c.Query("category")
-	search := c.Query("search")
-	limit, _ := strconv.Atoi(c.Query("limit"))
+	query := articleQuery(c)
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, next, err := a._ArticleService.GetAllArticles(query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch articles")
+	}
 
-	// Debug logging
-	println("=== Controller GetAll ===")
-	println("Raw category from query:", category)
-	println("Category bytes:", []byte(category))
-	println("=========================")
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.Article]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get articles successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+		NextCursor:   next,
+	})
+}
 
-	items, err := a._ArticleService.GetAllArticles(category, search, limit)
+func (a *ArticleController) Search(c *fiber.Ctx) error {
+	query := &validation.QuerySearch{
+		Q:        c.Query("q"),
+		Page:     c.QueryInt("page", 1),
+		Limit:    c.QueryInt("limit", 10),
+		Category: c.Query("category"),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, err := a._ArticleService.Search(query)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
-			Code:    fiber.StatusInternalServerError,
-			Status:  "error",
-			Message: "could not fetch articles",
-			Errors:  err.Error(),
-		})
+		return serviceError(c, err, "could not search articles")
 	}
-	return c.Status(fiber.StatusOK).JSON(items)
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.SearchResult]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Search articles successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
 }
 
 func (a *ArticleController) GetByID(c *fiber.Ctx) error {
@@ -101,7 +131,7 @@ func (a *ArticleController) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	item, err := a._ArticleService.GetByID(id)
+	item, err := a._ArticleService.GetPublishedByID(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(response.ErrorDetails{
 			Code:    fiber.StatusNotFound,
@@ -111,6 +141,27 @@ func (a *ArticleController) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
+	countView(a._ViewService, c, item)
+	c.Set(fiber.HeaderETag, articleETag(item))
+	return c.Status(fiber.StatusOK).JSON(item)
+}
+
+func (a *ArticleController) GetBySlug(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+
+	item, canonical, err := a._ArticleService.GetPublishedBySlug(slug)
+	if err != nil {
+		return serviceError(c, err, "could not fetch article")
+	}
+
+	// Old slugs redirect permanently so shared links never break
+	if canonical != "" {
+		path := strings.TrimSuffix(c.Path(), slug) + canonical
+		return c.Redirect(path, fiber.StatusMovedPermanently)
+	}
+
+	countView(a._ViewService, c, item)
+	c.Set(fiber.HeaderETag, articleETag(item))
 	return c.Status(fiber.StatusOK).JSON(item)
 }
 
@@ -120,19 +171,117 @@ func (a *ArticleController) DeleteByID(c *fiber.Ctx) error {
 		idStr = c.Query("id")
 	}
 
-	if err := a._ArticleService.DeleteByID(idStr); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
-			Code:    fiber.StatusInternalServerError,
-			Status:  "error",
-			Message: "could not delete article",
+	actor, _ := c.Locals("user").(*model.User)
+	if err := a._ArticleService.DeleteByID(idStr, actor); err != nil {
+		return serviceError(c, err, "could not delete article")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Common{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "moved to trash",
+	})
+}
+
+// Bulk applies one operation to many articles; with dry_run it only reports
+// what would change
+func (a *ArticleController) Bulk(c *fiber.Ctx) error {
+	req := new(validation.BulkArticles)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
 			Errors:  err.Error(),
 		})
 	}
 
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	result, err := a._ArticleService.Bulk(req, actor)
+	if err != nil {
+		return serviceError(c, err, "could not run bulk operation")
+	}
+
+	// Nothing was applied because some articles failed
+	status := fiber.StatusOK
+	if !result.DryRun && !result.Applied {
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(result)
+}
+
+// GetTrash lists deleted articles for restoring or purging
+func (a *ArticleController) GetTrash(c *fiber.Ctx) error {
+	query := &validation.QueryTrash{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+		Search: c.Query("search"),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, err := a._ArticleService.GetTrash(query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch trash")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.Article]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get trash successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
+}
+
+func (a *ArticleController) Restore(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	restored, err := a._ArticleService.Restore(id, actor)
+	if err != nil {
+		return serviceError(c, err, "could not restore article")
+	}
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// Purge deletes a trashed article permanently
+func (a *ArticleController) Purge(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := a._ArticleService.Purge(id); err != nil {
+		return serviceError(c, err, "could not purge article")
+	}
+
 	return c.Status(fiber.StatusOK).JSON(response.Common{
 		Code:    fiber.StatusOK,
 		Status:  "success",
-		Message: "deleted",
+		Message: "purged",
 	})
 }
 
@@ -147,84 +296,434 @@ func (a *ArticleController) UpdateArticle(c *fiber.Ctx) error {
 		})
 	}
 
-	updated, err := a._ArticleService.UpdateArticle(req)
+	version, failure := editedVersion(c, req.ID, req.Version)
+	if failure != nil {
+		return c.Status(failure.Code).JSON(failure)
+	}
+	req.Version = version
+
+	// An embargo field left out of the request keeps its value; null clears
+	// it. A form cannot send null, so there only a value counts as sent.
+	var sent map[string]json.RawMessage
+	if json.Unmarshal(c.Body(), &sent) != nil {
+		sent = map[string]json.RawMessage{}
+		if req.PublishAt != nil {
+			sent["publish_at"] = nil
+		}
+		if req.ExpireAt != nil {
+			sent["expire_at"] = nil
+		}
+	}
+	var omitted []string
+	for _, field := range []string{"publish_at", "expire_at"} {
+		if _, ok := sent[field]; !ok {
+			omitted = append(omitted, field)
+		}
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	updated, err := a._ArticleService.UpdateArticle(req, actor, omitted...)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
-			Code:    fiber.StatusInternalServerError,
-			Status:  "error",
-			Message: "could not update article",
+		var stale *service.StaleArticleError
+		if errors.As(err, &stale) {
+			c.Set(fiber.HeaderETag, articleETag(stale.Current))
+			return c.Status(fiber.StatusPreconditionFailed).JSON(response.ErrorDetails{
+				Code:    fiber.StatusPreconditionFailed,
+				Status:  "fail",
+				Message: "article was changed by someone else",
+				Errors:  stale.Current,
+			})
+		}
+
+		return serviceError(c, err, "could not update article")
+	}
+
+	c.Set(fiber.HeaderETag, articleETag(updated))
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
+
+func (a *ArticleController) GetRelated(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
 			Errors:  err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(updated)
+	items, err := a._ArticleService.GetRelated(id, c.QueryInt("limit", 5))
+	if err != nil {
+		return serviceError(c, err, "could not fetch related articles")
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+func (a *ArticleController) GetMostRead(c *fiber.Ctx) error {
+	query := &validation.QueryMostRead{
+		Window:   c.Query("window", "day"),
+		Category: c.Query("category"),
+		Limit:    c.QueryInt("limit", 10),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, err := a._ViewService.MostRead(query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch most read articles")
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// countView records a reader's view. Browser prefetches are not views.
+func countView(v service.ViewService, c *fiber.Ctx, item *model.Article) {
+	if c.Get("Purpose") == "prefetch" || c.Get("Sec-Purpose") != "" {
+		return
+	}
+	v.Record(item.ID, c.IP(), c.Get(fiber.HeaderUserAgent))
 }
 
 func (a *ArticleController) GetFeatured(c *fiber.Ctx) error {
+	query := articleQuery(c)
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, next, err := a._ArticleService.GetFeatured(query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch featured articles")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.Article]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get featured articles successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+		NextCursor:   next,
+	})
+}
+
+// articleQuery reads the listing parameters shared by /articles and /featured.
+func articleQuery(c *fiber.Ctx) *validation.QueryArticle {
+	return &validation.QueryArticle{
+		Page:     c.QueryInt("page", 1),
+		Limit:    c.QueryInt("limit", 20),
+		Search:   c.Query("search"),
+		Category: c.Query("category"),
+		Cursor:   c.Query("cursor"),
+	}
+}
+
+// UploadImage stores an image for an article, with an optional description
+// for the media library. Rejections name the problem under errors.image.
+func (a *ArticleController) UploadImage(c *fiber.Ctx) error {
+	file, err := c.FormFile("image")
+	if err != nil {
+		return imageError(c, fiber.NewError(fiber.StatusBadRequest, "image is required"))
+	}
+
+	details := mediaFormDetails(c)
+	if err := validation.Validator().Struct(details); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	image, err := a._ImageService.Upload(file, details, actor)
+	if err != nil {
+		return imageError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.ImageUploadResponse{
+		Message: "ছবি সফলভাবে আপলোড হয়েছে",
+		URL:     image.URL,
+		Image:   image,
+	})
+}
+
+// GetImage reports an upload's variants; the upload response links here
+// while they are still being made.
+func (a *ArticleController) GetImage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	image, err := a._ImageService.Get(id)
+	if err != nil {
+		return serviceError(c, err, "could not load image")
+	}
+	return c.JSON(image)
+}
+
+// mediaFormDetails reads the description of the photo that may come with an
+// upload, in the form fields alt, caption, credit and license.
+func mediaFormDetails(c *fiber.Ctx) *validation.MediaDetails {
+	details := &validation.MediaDetails{}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return details
+	}
+	value := func(key string) *string {
+		if values := form.Value[key]; len(values) > 0 {
+			return &values[0]
+		}
+		return nil
+	}
+	details.Alt = value("alt")
+	details.Caption = value("caption")
+	details.Credit = value("credit")
+	details.License = value("license")
+	return details
+}
+
+func imageError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code >= fiber.StatusInternalServerError {
+		return serviceError(c, err, "could not save image")
+	}
+	return c.Status(fiberErr.Code).JSON(response.ErrorDetails{
+		Code:    fiberErr.Code,
+		Status:  "fail",
+		Message: "invalid image",
+		Errors:  map[string]string{"image": fiberErr.Message},
+	})
+}
+
+func (a *ArticleController) GetDashboard(c *fiber.Ctx) error {
+	status := c.Query("status")
 	category := c.Query("category")
+	search := c.Query("search")
 	limit, _ := strconv.Atoi(c.Query("limit"))
 
-	items, err := a._ArticleService.GetFeatured(category, limit)
+	items, err := a._ArticleService.GetDashboardArticles(status, category, search, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
 			Code:    fiber.StatusInternalServerError,
 			Status:  "error",
-			Message: "could not fetch featured articles",
+			Message: "could not fetch articles",
 			Errors:  err.Error(),
 		})
 	}
 	return c.Status(fiber.StatusOK).JSON(items)
 }
 
-func (a *ArticleController) GetCategories(c *fiber.Ctx) error {
-	items, err := a._ArticleService.GetCategories()
+func (a *ArticleController) Transition(c *fiber.Ctx) error {
+	req := new(validation.ArticleTransition)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	updated, err := a._ArticleService.TransitionArticle(req, actor)
+	if err != nil {
+		return serviceError(c, err, "could not change article state")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(updated)
+}
+
+func (a *ArticleController) GetHistory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	items, err := a._ArticleService.GetTransitions(id)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
 			Code:    fiber.StatusInternalServerError,
 			Status:  "error",
-			Message: "could not fetch categories",
+			Message: "could not fetch article history",
 			Errors:  err.Error(),
 		})
 	}
 	return c.Status(fiber.StatusOK).JSON(items)
 }
 
-func (a *ArticleController) UploadImage(c *fiber.Ctx) error {
-	file, err := c.FormFile("image")
+// articleETag identifies one version of one article, e.g. "42-7".
+func articleETag(item *model.Article) string {
+	return fmt.Sprintf("\"%d-%d\"", item.ID, item.Version)
+}
+
+// editedVersion is the version of the article an edit was made to: If-Match,
+// which takes precedence, or the version in the body. Without either the
+// edit could silently overwrite someone else's, so it is refused; If-Match: *
+// asks for that on purpose and yields 0, which overwrites any version.
+func editedVersion(c *fiber.Ctx, id, version int) (int, *response.ErrorDetails) {
+	ifMatch := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	switch {
+	case ifMatch == "*":
+		return 0, nil
+	case ifMatch != "":
+		version, ok := parseArticleETag(ifMatch, id)
+		if !ok {
+			return 0, &response.ErrorDetails{
+				Code:    fiber.StatusPreconditionFailed,
+				Status:  "fail",
+				Message: "If-Match does not match this article",
+				Errors:  ifMatch,
+			}
+		}
+		return version, nil
+	case version <= 0:
+		return 0, &response.ErrorDetails{
+			Code:    fiber.StatusPreconditionRequired,
+			Status:  "fail",
+			Message: "send the version being edited, or If-Match",
+		}
+	}
+	return version, nil
+}
+
+// parseArticleETag extracts the version from an If-Match value produced by
+// articleETag, checking it belongs to article id.
+func parseArticleETag(value string, id int) (int, bool) {
+	value = strings.Trim(strings.TrimPrefix(strings.TrimSpace(value), "W/"), "\"")
+
+	idPart, versionPart, found := strings.Cut(value, "-")
+	if !found || idPart != strconv.Itoa(id) {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(versionPart)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+func totalPages(total int64, limit int) int64 {
+	if limit <= 0 {
+		return 0
+	}
+	return (total + int64(limit) - 1) / int64(limit)
+}
+
+// serviceError writes err as an ErrorDetails response, keeping the status
+// code and message of *fiber.Error values raised by the service layer.
+func serviceError(c *fiber.Ctx, err error, message string) error {
+	code := fiber.StatusInternalServerError
+	status := "error"
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code = fiberErr.Code
+		message = fiberErr.Message
+		if code < fiber.StatusInternalServerError {
+			status = "fail"
+		}
+	}
+
+	return c.Status(code).JSON(response.ErrorDetails{
+		Code:    code,
+		Status:  status,
+		Message: message,
+		Errors:  err.Error(),
+	})
+}
+
+func (a *ArticleController) GetRevisions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Query("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
 			Code:    fiber.StatusBadRequest,
 			Status:  "fail",
-			Message: "image is required",
+			Message: "invalid id",
 			Errors:  err.Error(),
 		})
 	}
 
-	uploadsDir := "frontend/uploads"
-	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
+	items, err := a._ArticleService.GetRevisions(id)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
 			Code:    fiber.StatusInternalServerError,
 			Status:  "error",
-			Message: "could not create uploads directory",
+			Message: "could not fetch revisions",
+			Errors:  err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+func (a *ArticleController) DiffRevisions(c *fiber.Ctx) error {
+	id, errID := strconv.Atoi(c.Query("id"))
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if err := errors.Join(errID, errFrom, errTo); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "id, from and to are required",
 			Errors:  err.Error(),
 		})
 	}
 
-	filename := fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename)
-	filePath := filepath.Join(uploadsDir, filename)
+	diff, err := a._ArticleService.DiffRevisions(id, from, to)
+	if err != nil {
+		return serviceError(c, err, "could not compare revisions")
+	}
+	return c.Status(fiber.StatusOK).JSON(diff)
+}
 
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
-			Code:    fiber.StatusInternalServerError,
-			Status:  "error",
-			Message: "could not save file",
+func (a *ArticleController) RestoreRevision(c *fiber.Ctx) error {
+	req := new(validation.RestoreRevision)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
 			Errors:  err.Error(),
 		})
 	}
 
-	url := "/frontend/uploads/" + filename
-	return c.Status(fiber.StatusOK).JSON(model.ImageUploadResponse{
-		Message: "ছবি সফলভাবে আপলোড হয়েছে",
-		URL:     url,
-	})
+	version, failure := editedVersion(c, req.ID, req.Version)
+	if failure != nil {
+		return c.Status(failure.Code).JSON(failure)
+	}
+	req.Version = version
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	restored, err := a._ArticleService.RestoreRevision(req, actor)
+	if err != nil {
+		var stale *service.StaleArticleError
+		if errors.As(err, &stale) {
+			c.Set(fiber.HeaderETag, articleETag(stale.Current))
+			return c.Status(fiber.StatusPreconditionFailed).JSON(response.ErrorDetails{
+				Code:    fiber.StatusPreconditionFailed,
+				Status:  "fail",
+				Message: "article was changed by someone else",
+				Errors:  stale.Current,
+			})
+		}
+
+		return serviceError(c, err, "could not restore revision")
+	}
+
+	c.Set(fiber.HeaderETag, articleETag(restored))
+	return c.Status(fiber.StatusOK).JSON(restored)
 }
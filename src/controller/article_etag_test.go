@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"app/src/model"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestArticleETagRoundTrip(t *testing.T) {
+	etag := articleETag(&model.Article{ID: 42, Version: 7})
+	assert.Equal(t, `"42-7"`, etag)
+
+	version, ok := parseArticleETag(etag, 42)
+	assert.True(t, ok)
+	assert.Equal(t, 7, version)
+}
+
+func TestParseArticleETag(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		id      int
+		version int
+		ok      bool
+	}{
+		{name: "quoted", value: `"42-7"`, id: 42, version: 7, ok: true},
+		{name: "unquoted", value: `42-7`, id: 42, version: 7, ok: true},
+		{name: "weak", value: `W/"42-7"`, id: 42, version: 7, ok: true},
+		{name: "surrounding space", value: ` "42-7" `, id: 42, version: 7, ok: true},
+		{name: "another article", value: `"41-7"`, id: 42},
+		{name: "id prefix of another", value: `"4-27"`, id: 42},
+		{name: "no version", value: `"42"`, id: 42},
+		{name: "empty version", value: `"42-"`, id: 42},
+		{name: "zero version", value: `"42-0"`, id: 42},
+		{name: "negative version", value: `"42--1"`, id: 42},
+		{name: "not a number", value: `"42-seven"`, id: 42},
+		{name: "empty", value: ``, id: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok := parseArticleETag(tt.value, tt.id)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestEditedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		body    int
+		version int
+		status  int
+	}{
+		{name: "version in the body", body: 7, version: 7},
+		{name: "If-Match over the body", ifMatch: `"42-8"`, body: 7, version: 8},
+		{name: "If-Match any version", ifMatch: "*", version: 0},
+		{name: "If-Match of another article", ifMatch: `"41-8"`, status: fiber.StatusPreconditionFailed},
+		{name: "neither", status: fiber.StatusPreconditionRequired},
+	}
+
+	app := fiber.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(c)
+			if tt.ifMatch != "" {
+				c.Request().Header.Set(fiber.HeaderIfMatch, tt.ifMatch)
+			}
+
+			version, failure := editedVersion(c, 42, tt.body)
+			if tt.status != 0 {
+				if assert.NotNil(t, failure) {
+					assert.Equal(t, tt.status, failure.Code)
+				}
+				return
+			}
+			assert.Nil(t, failure)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BreakingNewsController struct {
+	_BreakingNewsService service.BreakingNewsService
+	_EventBroker         service.EventBroker
+}
+
+func NewBreakingNewsController(s service.BreakingNewsService, e service.EventBroker) *BreakingNewsController {
+	return &BreakingNewsController{_BreakingNewsService: s, _EventBroker: e}
+}
+
+// GetLive returns what is on the ticker now
+func (b *BreakingNewsController) GetLive(c *fiber.Ctx) error {
+	items, err := b._BreakingNewsService.GetLive()
+	if err != nil {
+		return serviceError(c, err, "could not fetch breaking news")
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// Stream pushes ticker changes to the reader (Server-Sent Events)
+func (b *BreakingNewsController) Stream(c *fiber.Ctx) error {
+	return streamEvents(c, b._EventBroker, service.BreakingNewsTopic, func() (interface{}, error) {
+		return b._BreakingNewsService.GetLive()
+	})
+}
+
+// GetAll lists every item, newest first, for the dashboard
+func (b *BreakingNewsController) GetAll(c *fiber.Ctx) error {
+	query := &validation.QueryBreakingNews{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 20),
+		Status: c.Query("status"),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, err := b._BreakingNewsService.GetAll(query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch breaking news")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.BreakingNews]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get breaking news successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
+}
+
+func (b *BreakingNewsController) Create(c *fiber.Ctx) error {
+	req := new(validation.CreateBreakingNews)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	item, err := b._BreakingNewsService.Create(req, actor)
+	if err != nil {
+		return serviceError(c, err, "could not create breaking news")
+	}
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+func (b *BreakingNewsController) Expire(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("breakingId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	item, err := b._BreakingNewsService.Expire(id, actor)
+	if err != nil {
+		return serviceError(c, err, "could not expire breaking news")
+	}
+	return c.Status(fiber.StatusOK).JSON(item)
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CategoryController struct {
+	_CategoryService service.CategoryService
+}
+
+func NewCategoryController(s service.CategoryService) *CategoryController {
+	return &CategoryController{_CategoryService: s}
+}
+
+// Get All (active only unless ?all=true)
+func (cc *CategoryController) GetAll(c *fiber.Ctx) error {
+	items, err := cc._CategoryService.GetAll(c.QueryBool("all"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
+			Code:    fiber.StatusInternalServerError,
+			Status:  "error",
+			Message: "could not fetch categories",
+			Errors:  err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// Create
+func (cc *CategoryController) Create(c *fiber.Ctx) error {
+	req := new(validation.CreateCategory)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	category, err := cc._CategoryService.Create(req)
+	if err != nil {
+		return serviceError(c, err, "could not create category")
+	}
+	return c.Status(fiber.StatusCreated).JSON(category)
+}
+
+// Update
+func (cc *CategoryController) Update(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("categoryId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	req := new(validation.UpdateCategory)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	category, err := cc._CategoryService.Update(id, req)
+	if err != nil {
+		return serviceError(c, err, "could not update category")
+	}
+	return c.Status(fiber.StatusOK).JSON(category)
+}
+
+// Delete
+func (cc *CategoryController) Delete(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("categoryId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := cc._CategoryService.Delete(id); err != nil {
+		return serviceError(c, err, "could not delete category")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Common{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "deleted",
+	})
+}
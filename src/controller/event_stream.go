@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/service"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamHeartbeat keeps idle connections from being closed by proxies.
+const streamHeartbeat = 20 * time.Second
+
+// streamEvents answers with a Server-Sent Events stream of topic. A browser
+// that reconnects sends the last id it saw (Last-Event-ID) and is sent what
+// it missed; a new reader, or one whose events are gone, first gets a
+// "snapshot" event built by snapshot.
+func streamEvents(c *fiber.Ctx, broker service.EventBroker, topic string, snapshot func() (interface{}, error)) error {
+	lastID, _ := strconv.ParseInt(c.Get("Last-Event-ID", c.Query("last_event_id")), 10, 64)
+
+	// Subscribe before looking back so nothing falls in between; duplicates
+	// are skipped by id below
+	sub := broker.Subscribe(topic)
+
+	var backlog []model.StreamEvent
+	replayed := false
+	if lastID > 0 {
+		events, ok, err := broker.Since(topic, lastID)
+		if err != nil {
+			sub.Close()
+			return serviceError(c, err, "could not open stream")
+		}
+		backlog, replayed = events, ok
+	}
+	if !replayed {
+		id, err := broker.LastID()
+		if err != nil {
+			sub.Close()
+			return serviceError(c, err, "could not open stream")
+		}
+		data, err := snapshot()
+		if err != nil {
+			sub.Close()
+			return serviceError(c, err, "could not open stream")
+		}
+		payload, err := json.Marshal(data)
+		if err != nil {
+			sub.Close()
+			return err
+		}
+		backlog = []model.StreamEvent{{ID: id, Type: "snapshot", Data: string(payload)}}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		sent := int64(0)
+		fmt.Fprint(w, "retry: 3000\n\n")
+		for _, event := range backlog {
+			writeEvent(w, event)
+			sent = event.ID
+		}
+		if w.Flush() != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				if event.ID <= sent {
+					continue
+				}
+				writeEvent(w, event)
+				sent = event.ID
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			// A write error means the reader has gone
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeEvent(w *bufio.Writer, event model.StreamEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
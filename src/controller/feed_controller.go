@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Feeds carry the feedSize most recent articles. Rendered feeds are kept
+// until an article changes or feedTTL passes (category renames).
+const (
+	feedSize    = 50
+	feedTTL     = 5 * time.Minute
+	feedExcerpt = 300
+)
+
+type feedCache struct {
+	mu      sync.Mutex
+	version int64
+	entries map[string]xmlDocument
+}
+
+func (c *feedCache) get(key string) (xmlDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != service.ArticleVersion() {
+		return xmlDocument{}, false
+	}
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.built) > feedTTL {
+		return xmlDocument{}, false
+	}
+	return entry, true
+}
+
+func (c *feedCache) put(key string, entry xmlDocument, version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || c.version != version {
+		c.version = version
+		c.entries = make(map[string]xmlDocument)
+	}
+	c.entries[key] = entry
+}
+
+type FeedController struct {
+	_ArticleService  service.ArticleService
+	_CategoryService service.CategoryService
+	_MediaService    service.MediaService
+	cache            feedCache
+}
+
+func NewFeedController(a service.ArticleService, c service.CategoryService, m service.MediaService) *FeedController {
+	return &FeedController{_ArticleService: a, _CategoryService: c, _MediaService: m}
+}
+
+func (f *FeedController) RSS(c *fiber.Ctx) error {
+	return f.serve(c, "rss", "application/rss+xml; charset=utf-8")
+}
+
+func (f *FeedController) Atom(c *fiber.Ctx) error {
+	return f.serve(c, "atom", "application/atom+xml; charset=utf-8")
+}
+
+// serve answers from the cache when it can; see sendXML for conditional GET.
+func (f *FeedController) serve(c *fiber.Ctx, format, contentType string) error {
+	slug, err := url.PathUnescape(c.Params("category"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(response.ErrorDetails{
+			Code:    fiber.StatusNotFound,
+			Status:  "fail",
+			Message: "category not found",
+		})
+	}
+	key := format + ":" + slug
+
+	entry, ok := f.cache.get(key)
+	if !ok {
+		version := service.ArticleVersion()
+		entry, err = f.build(c, format, slug)
+		if err != nil {
+			return serviceError(c, err, "could not build feed")
+		}
+		f.cache.put(key, entry, version)
+	}
+
+	return sendXML(c, entry, contentType)
+}
+
+func (f *FeedController) build(c *fiber.Ctx, format, slug string) (xmlDocument, error) {
+	title := config.SiteName
+	params := &validation.QueryArticle{Limit: feedSize}
+	if slug != "" {
+		cat, err := f._CategoryService.GetBySlug(slug)
+		if err != nil {
+			return xmlDocument{}, err
+		}
+		title += " - " + cat.NameBn
+		params.Category = cat.Slug
+	}
+
+	items, _, _, err := f._ArticleService.GetAllArticles(params)
+	if err != nil {
+		return xmlDocument{}, err
+	}
+
+	// Last-Modified is the newest change among the listed articles
+	modified := time.Unix(0, 0)
+	for _, item := range items {
+		if item.UpdatedAt.After(modified) {
+			modified = item.UpdatedAt
+		}
+	}
+
+	// Enclosures need the image size, known for files in the media library
+	images := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Image != nil && *item.Image != "" {
+			images = append(images, *item.Image)
+		}
+	}
+	sizes, err := f._MediaService.Sizes(images)
+	if err != nil {
+		return xmlDocument{}, err
+	}
+
+	self := config.AppURL + c.Path()
+	var doc interface{}
+	if format == "atom" {
+		doc = atomFeed(title, self, modified, items, sizes)
+	} else {
+		doc = rssFeed(title, self, modified, items, sizes)
+	}
+
+	return newXMLDocument(doc, modified)
+}
+
+func rssFeed(title, self string, modified time.Time, items []model.Article, sizes map[string]int64) response.RSS {
+	feed := response.RSS{
+		Version:      "2.0",
+		AtomNS:       "http://www.w3.org/2005/Atom",
+		ContentNS:    "http://purl.org/rss/1.0/modules/content/",
+		DublinCoreNS: "http://purl.org/dc/elements/1.1/",
+		Channel: response.RSSChannel{
+			Title:         title,
+			Link:          config.AppURL + "/",
+			Description:   title,
+			Language:      "bn",
+			LastBuildDate: modified.Format(time.RFC1123Z),
+			AtomLink:      response.AtomLink{Href: self, Rel: "self", Type: "application/rss+xml"},
+			Items:         make([]response.RSSItem, 0, len(items)),
+		},
+	}
+
+	for i := range items {
+		a := &items[i]
+		link := service.ArticleURL(a)
+		item := response.RSSItem{
+			Title:       a.Title,
+			Link:        link,
+			GUID:        response.RSSGUID{IsPermaLink: true, Value: service.ArticlePermalink(a)},
+			Description: utils.Excerpt(a.Content, feedExcerpt),
+			Content:     response.CDATA{Value: a.Content},
+			Creator:     a.Author,
+			Category:    a.Category,
+			PubDate:     a.Created.Format(time.RFC1123Z),
+		}
+		if size, ok := imageSize(a, sizes); ok {
+			item.Enclosure = &response.RSSEnclosure{URL: service.AbsoluteURL(*a.Image), Length: size, Type: imageType(*a.Image)}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return feed
+}
+
+func atomFeed(title, self string, modified time.Time, items []model.Article, sizes map[string]int64) response.AtomFeed {
+	feed := response.AtomFeed{
+		Lang:    "bn",
+		Title:   title,
+		ID:      self,
+		Updated: modified.Format(time.RFC3339),
+		Links: []response.AtomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: config.AppURL + "/", Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]response.AtomEntry, 0, len(items)),
+	}
+
+	for i := range items {
+		a := &items[i]
+		link := service.ArticleURL(a)
+		entry := response.AtomEntry{
+			Title:     a.Title,
+			ID:        service.ArticlePermalink(a),
+			Links:     []response.AtomLink{{Href: link, Rel: "alternate", Type: "text/html"}},
+			Published: a.Created.Format(time.RFC3339),
+			Updated:   a.UpdatedAt.Format(time.RFC3339),
+			Author:    response.AtomPerson{Name: a.Author},
+			Summary:   response.AtomText{Type: "text", Value: utils.Excerpt(a.Content, feedExcerpt)},
+			Content:   response.AtomText{Type: "html", Value: a.Content},
+		}
+		if a.Category != "" {
+			entry.Category = &response.AtomTerm{Term: a.Category}
+		}
+		if size, ok := imageSize(a, sizes); ok {
+			entry.Links = append(entry.Links, response.AtomLink{Href: service.AbsoluteURL(*a.Image), Rel: "enclosure", Type: imageType(*a.Image), Length: size})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// imageSize is the size of the main image of a, when it is known. Images
+// linked from elsewhere have none and get no enclosure.
+func imageSize(a *model.Article, sizes map[string]int64) (int64, bool) {
+	if a.Image == nil || *a.Image == "" {
+		return 0, false
+	}
+	size, ok := sizes[*a.Image]
+	return size, ok && size > 0
+}
+
+func imageType(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "image/jpeg"
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LiveBlogController struct {
+	_LiveBlogService service.LiveBlogService
+	_EventBroker     service.EventBroker
+}
+
+func NewLiveBlogController(s service.LiveBlogService, e service.EventBroker) *LiveBlogController {
+	return &LiveBlogController{_LiveBlogService: s, _EventBroker: e}
+}
+
+func invalidID(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+		Code:    fiber.StatusBadRequest,
+		Status:  "fail",
+		Message: "invalid id",
+		Errors:  err.Error(),
+	})
+}
+
+func invalidJSON(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+		Code:    fiber.StatusBadRequest,
+		Status:  "fail",
+		Message: "invalid json",
+		Errors:  err.Error(),
+	})
+}
+
+// GetByArticle finds the live blog of ?article_id=
+func (l *LiveBlogController) GetByArticle(c *fiber.Ctx) error {
+	articleID := c.QueryInt("article_id")
+	if articleID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "article_id is required",
+		})
+	}
+
+	blog, err := l._LiveBlogService.GetPublicByArticle(articleID)
+	if err != nil {
+		return serviceError(c, err, "could not fetch live blog")
+	}
+	return c.Status(fiber.StatusOK).JSON(blog)
+}
+
+func (l *LiveBlogController) Get(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	blog, err := l._LiveBlogService.GetPublic(id)
+	if err != nil {
+		return serviceError(c, err, "could not fetch live blog")
+	}
+	return c.Status(fiber.StatusOK).JSON(blog)
+}
+
+// GetEntries lists entries newest first; ?since=<RFC 3339 time> returns only
+// those posted after it
+func (l *LiveBlogController) GetEntries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	query := &validation.QueryLiveBlogEntries{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 20),
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+				Code:    fiber.StatusBadRequest,
+				Status:  "fail",
+				Message: "since must be an RFC 3339 time",
+				Errors:  err.Error(),
+			})
+		}
+		query.Since = &t
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, err := l._LiveBlogService.GetEntries(id, query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch live blog entries")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.LiveBlogEntry]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get live blog entries successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
+}
+
+// Stream pushes new, edited and deleted entries (Server-Sent Events)
+func (l *LiveBlogController) Stream(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	// Readers may only follow blogs they can see
+	if _, err := l._LiveBlogService.GetPublic(id); err != nil {
+		return serviceError(c, err, "could not fetch live blog")
+	}
+
+	return streamEvents(c, l._EventBroker, service.LiveBlogTopic(id), func() (interface{}, error) {
+		return l._LiveBlogService.Snapshot(id)
+	})
+}
+
+func (l *LiveBlogController) Create(c *fiber.Ctx) error {
+	req := new(validation.CreateLiveBlog)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	blog, err := l._LiveBlogService.Create(req, actor)
+	if err != nil {
+		return serviceError(c, err, "could not create live blog")
+	}
+	return c.Status(fiber.StatusCreated).JSON(blog)
+}
+
+func (l *LiveBlogController) Update(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	req := new(validation.UpdateLiveBlog)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	blog, err := l._LiveBlogService.Update(id, req)
+	if err != nil {
+		return serviceError(c, err, "could not update live blog")
+	}
+	return c.Status(fiber.StatusOK).JSON(blog)
+}
+
+func (l *LiveBlogController) AddEntry(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	req := new(validation.CreateLiveBlogEntry)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	entry, err := l._LiveBlogService.AddEntry(id, req, actor)
+	if err != nil {
+		return serviceError(c, err, "could not add live blog entry")
+	}
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+func (l *LiveBlogController) UpdateEntry(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+	entryID, err := c.ParamsInt("entryId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	req := new(validation.UpdateLiveBlogEntry)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	entry, err := l._LiveBlogService.UpdateEntry(id, entryID, req)
+	if err != nil {
+		return serviceError(c, err, "could not update live blog entry")
+	}
+	return c.Status(fiber.StatusOK).JSON(entry)
+}
+
+func (l *LiveBlogController) DeleteEntry(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("liveBlogId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+	entryID, err := c.ParamsInt("entryId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	if err := l._LiveBlogService.DeleteEntry(id, entryID); err != nil {
+		return serviceError(c, err, "could not delete live blog entry")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Common{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "deleted",
+	})
+}
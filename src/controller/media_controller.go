@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MediaController struct {
+	_MediaService service.MediaService
+}
+
+func NewMediaController(s service.MediaService) *MediaController {
+	return &MediaController{_MediaService: s}
+}
+
+// GetAll searches the media library; ?unused=true lists only what nothing
+// uses
+func (m *MediaController) GetAll(c *fiber.Ctx) error {
+	query := &validation.QueryMedia{
+		Page:       c.QueryInt("page", 1),
+		Limit:      c.QueryInt("limit", 20),
+		Search:     c.Query("search"),
+		Type:       c.Query("type"),
+		Credit:     c.Query("credit"),
+		License:    c.Query("license"),
+		UploadedBy: c.QueryInt("uploaded_by"),
+		Status:     c.Query("status"),
+		Unused:     c.QueryBool("unused"),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, err := m._MediaService.List(query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch media")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.Media]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get media successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
+}
+
+func (m *MediaController) Get(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("mediaId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	media, err := m._MediaService.Get(id)
+	if err != nil {
+		return serviceError(c, err, "could not fetch media")
+	}
+	return c.Status(fiber.StatusOK).JSON(media)
+}
+
+func (m *MediaController) Update(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("mediaId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	req := new(validation.MediaDetails)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	media, err := m._MediaService.Update(id, req)
+	if err != nil {
+		return serviceError(c, err, "could not update media")
+	}
+	return c.Status(fiber.StatusOK).JSON(media)
+}
+
+// GetUsage lists the articles, live blog entries and users using a photo
+func (m *MediaController) GetUsage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("mediaId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	usage, err := m._MediaService.Usage(id)
+	if err != nil {
+		return serviceError(c, err, "could not fetch media usage")
+	}
+	return c.Status(fiber.StatusOK).JSON(usage)
+}
+
+// Delete removes a photo and its files; one still in use is refused with 409
+func (m *MediaController) Delete(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("mediaId")
+	if err != nil {
+		return invalidID(c, err)
+	}
+
+	if err := m._MediaService.Delete(id); err != nil {
+		return serviceError(c, err, "could not delete media")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Common{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "deleted",
+	})
+}
@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/service"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	pageTemplates   = "./frontend/templates/*.html"
+	pageDescription = 160
+	categoryPerPage = 20
+	relatedOnPage   = 4
+)
+
+// pageMeta fills the <head>: title, canonical URL, Open Graph and Twitter
+// tags and the JSON-LD block.
+type pageMeta struct {
+	SiteName      string
+	Title         string
+	Description   string
+	Canonical     string
+	Image         string
+	Type          string
+	PublishedTime string
+	ModifiedTime  string
+	Section       string
+	Tags          []string
+	JSONLD        template.JS
+	RSS           string
+}
+
+type articlePage struct {
+	Meta      pageMeta
+	Article   *model.Article
+	Body      template.HTML
+	Published string
+	Image     string
+	Related   []model.RelatedArticle
+}
+
+type categoryPage struct {
+	Meta     pageMeta
+	Category *model.Category
+	Articles []model.Article
+	Page     int
+	PrevURL  string
+	NextURL  string
+}
+
+// PageController renders article and category pages on the server, so
+// crawlers and link previews see the content and its metadata. The pages
+// load app.js for search, categories and sharing.
+type PageController struct {
+	_ArticleService  service.ArticleService
+	_CategoryService service.CategoryService
+	_ViewService     service.ViewService
+	templates        *template.Template
+}
+
+func NewPageController(a service.ArticleService, c service.CategoryService, v service.ViewService) *PageController {
+	templates := template.Must(template.New("pages").Funcs(template.FuncMap{
+		"articleURL":   service.ArticleURL,
+		"banglaDate":   utils.BanglaDate,
+		"excerpt":      utils.Excerpt,
+		"imageAlt":     imageAlt,
+		"imageSrcset":  imageSrcset,
+		"imageURL":     imageURL,
+		"imageVariant": imageVariant,
+		"year":         func() string { return utils.BanglaDigits(strconv.Itoa(time.Now().Year())) },
+	}).ParseGlob(pageTemplates))
+
+	return &PageController{
+		_ArticleService:  a,
+		_CategoryService: c,
+		_ViewService:     v,
+		templates:        templates,
+	}
+}
+
+func (p *PageController) render(c *fiber.Ctx, status int, name string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := p.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(status).Send(buf.Bytes())
+}
+
+func (p *PageController) notFound(c *fiber.Ctx) error {
+	return p.render(c, fiber.StatusNotFound, "notfound.html", struct{ Meta pageMeta }{
+		Meta: pageMeta{
+			SiteName: config.SiteName,
+			Title:    "পাতাটি পাওয়া যায়নি - " + config.SiteName,
+		},
+	})
+}
+
+func (p *PageController) Article(c *fiber.Ctx) error {
+	slug, err := url.PathUnescape(c.Params("slug"))
+	if err != nil {
+		return p.notFound(c)
+	}
+
+	item, canonical, err := p._ArticleService.GetPublishedBySlug(slug)
+	if err != nil {
+		if isNotFound(err) {
+			return p.notFound(c)
+		}
+		return err
+	}
+
+	// Old slugs redirect permanently so shared links never break
+	if canonical != "" {
+		return c.Redirect("/news/"+url.PathEscape(canonical), fiber.StatusMovedPermanently)
+	}
+
+	related, err := p._ArticleService.GetRelated(item.ID, relatedOnPage)
+	if err != nil {
+		utils.Log.Errorf("Failed to load related articles for %d: %+v", item.ID, err)
+	}
+
+	countView(p._ViewService, c, item)
+
+	data := articlePage{
+		Article: item,
+		// Article bodies are HTML written by the newsroom in the dashboard
+		Body:      template.HTML(item.Content),
+		Published: utils.BanglaDate(publishedAt(item)),
+		Related:   related,
+	}
+	data.Image = imageVariant(item, model.MediaHero)
+	data.Meta = articleMeta(item, imageVariant(item, model.MediaOpenGraph))
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	return p.render(c, fiber.StatusOK, "article.html", data)
+}
+
+func articleMeta(a *model.Article, image string) pageMeta {
+	canonical := service.ArticleURL(a)
+	description := utils.Excerpt(a.Content, pageDescription)
+
+	meta := pageMeta{
+		SiteName:      config.SiteName,
+		Title:         a.Title,
+		Description:   description,
+		Canonical:     canonical,
+		Image:         image,
+		Type:          "article",
+		PublishedTime: publishedAt(a).Format(time.RFC3339),
+		ModifiedTime:  a.UpdatedAt.Format(time.RFC3339),
+		Section:       a.Category,
+	}
+	for _, tag := range a.Tags {
+		meta.Tags = append(meta.Tags, tag.Name)
+	}
+
+	ld := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "NewsArticle",
+		"mainEntityOfPage": map[string]string{"@type": "WebPage", "@id": canonical},
+		"headline":         utils.Excerpt(a.Title, 110),
+		"description":      description,
+		"datePublished":    meta.PublishedTime,
+		"dateModified":     meta.ModifiedTime,
+		"author":           []map[string]string{{"@type": "Person", "name": a.Author}},
+		"publisher":        map[string]string{"@type": "Organization", "name": config.SiteName, "url": config.AppURL + "/"},
+		"articleSection":   a.Category,
+		"inLanguage":       "bn",
+	}
+	if image != "" {
+		ld["image"] = []string{image}
+	}
+	if len(meta.Tags) > 0 {
+		ld["keywords"] = meta.Tags
+	}
+	meta.JSONLD = jsonLD(ld)
+
+	return meta
+}
+
+func (p *PageController) Category(c *fiber.Ctx) error {
+	slug, err := url.PathUnescape(c.Params("slug"))
+	if err != nil {
+		return p.notFound(c)
+	}
+
+	cat, err := p._CategoryService.GetBySlug(slug)
+	if err != nil {
+		if isNotFound(err) {
+			return p.notFound(c)
+		}
+		return err
+	}
+
+	query := &validation.QueryArticle{
+		Page:     c.QueryInt("page", 1),
+		Limit:    categoryPerPage,
+		Category: cat.Slug,
+	}
+	if query.Page < 1 {
+		return p.notFound(c)
+	}
+
+	items, total, _, err := p._ArticleService.GetAllArticles(query)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 && query.Page > 1 {
+		return p.notFound(c)
+	}
+
+	base := service.CategoryURL(cat)
+	data := categoryPage{
+		Category: cat,
+		Articles: items,
+		Page:     query.Page,
+	}
+	canonical := base
+	if query.Page > 1 {
+		canonical = base + "?page=" + strconv.Itoa(query.Page)
+		data.PrevURL = base
+		if query.Page > 2 {
+			data.PrevURL = base + "?page=" + strconv.Itoa(query.Page-1)
+		}
+	}
+	if int64(query.Page) < totalPages(total, query.Limit) {
+		data.NextURL = base + "?page=" + strconv.Itoa(query.Page+1)
+	}
+
+	elements := make([]map[string]interface{}, 0, len(items))
+	for i := range items {
+		elements = append(elements, map[string]interface{}{
+			"@type":    "ListItem",
+			"position": (query.Page-1)*query.Limit + i + 1,
+			"url":      service.ArticleURL(&items[i]),
+		})
+	}
+
+	data.Meta = pageMeta{
+		SiteName:    config.SiteName,
+		Title:       cat.NameBn + " - " + config.SiteName,
+		Description: cat.NameBn + " বিভাগের সর্বশেষ খবর",
+		Canonical:   canonical,
+		Type:        "website",
+		RSS:         config.AppURL + "/feed/" + url.PathEscape(cat.Slug) + "/rss.xml",
+		JSONLD: jsonLD(map[string]interface{}{
+			"@context":   "https://schema.org",
+			"@type":      "CollectionPage",
+			"name":       cat.NameBn,
+			"url":        canonical,
+			"inLanguage": "bn",
+			"mainEntity": map[string]interface{}{"@type": "ItemList", "itemListElement": elements},
+		}),
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	return p.render(c, fiber.StatusOK, "category.html", data)
+}
+
+// imageURL is the absolute address of an article image, or "" without one.
+func imageURL(image *string) string {
+	if image == nil || *image == "" {
+		return ""
+	}
+	return service.AbsoluteURL(*image)
+}
+
+// imageAlt describes an article's image from the media library, or by the
+// article's title when it has no description there.
+func imageAlt(a *model.Article) string {
+	if a.Images != nil && a.Images.Alt != "" {
+		return a.Images.Alt
+	}
+	return a.Title
+}
+
+// imageVariant is the absolute address of a sized copy of an article's
+// image, or of the image itself when there is no such copy.
+func imageVariant(a *model.Article, name string) string {
+	if url := a.Images.Variant(name, ""); url != "" {
+		return service.AbsoluteURL(url)
+	}
+	return imageURL(a.Image)
+}
+
+// imageSrcset lists the sized copies of an article's image in format, or
+// with "" in JPEG or PNG, for a srcset attribute.
+func imageSrcset(a *model.Article, format string) string {
+	if a.Images == nil {
+		return ""
+	}
+	if format != "" {
+		return a.Images.Srcset[format]
+	}
+	for f, srcset := range a.Images.Srcset {
+		if f != utils.ImageWebP {
+			return srcset
+		}
+	}
+	return ""
+}
+
+func isNotFound(err error) bool {
+	var fiberErr *fiber.Error
+	return errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound
+}
+
+// jsonLD encodes v for a <script type="application/ld+json"> block;
+// json.Marshal escapes <, > and &, so the script cannot be closed early.
+func jsonLD(v interface{}) template.JS {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return template.JS(b)
+}
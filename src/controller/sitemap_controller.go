@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const sitemapContentType = "application/xml; charset=utf-8"
+
+// The news and category sitemaps change with the clock as well as with
+// articles, so they are also rebuilt every sitemapTTL.
+const sitemapTTL = 5 * time.Minute
+
+// sitemapCache keeps each rendered sitemap with the fingerprint of the data
+// it was rendered from.
+type sitemapCache struct {
+	mu   sync.Mutex
+	docs map[string]sitemapEntry
+}
+
+type sitemapEntry struct {
+	fingerprint string
+	doc         xmlDocument
+}
+
+func (c *sitemapCache) get(key, fingerprint string) (xmlDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.docs[key]
+	return entry.doc, ok && entry.fingerprint == fingerprint
+}
+
+func (c *sitemapCache) put(key, fingerprint string, doc xmlDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.docs == nil {
+		c.docs = make(map[string]sitemapEntry)
+	}
+	c.docs[key] = sitemapEntry{fingerprint: fingerprint, doc: doc}
+}
+
+type SitemapController struct {
+	_SitemapService service.SitemapService
+	cache           sitemapCache
+}
+
+func NewSitemapController(s service.SitemapService) *SitemapController {
+	return &SitemapController{_SitemapService: s}
+}
+
+// cached serves key from the cache while fingerprint matches and renders it
+// with build otherwise.
+func (s *SitemapController) cached(c *fiber.Ctx, key, fingerprint string, build func() (xmlDocument, error)) error {
+	doc, ok := s.cache.get(key, fingerprint)
+	if !ok {
+		var err error
+		if doc, err = build(); err != nil {
+			return serviceError(c, err, "could not build sitemap")
+		}
+		s.cache.put(key, fingerprint, doc)
+	}
+	return sendXML(c, doc, sitemapContentType)
+}
+
+// timeBucket changes every sitemapTTL, expiring clock-dependent sitemaps.
+func timeBucket() string {
+	return strconv.FormatInt(time.Now().Unix()/int64(sitemapTTL.Seconds()), 10)
+}
+
+func (s *SitemapController) Index(c *fiber.Ctx) error {
+	pages, err := s._SitemapService.ArticlePages()
+	if err != nil {
+		return serviceError(c, err, "could not build sitemap")
+	}
+
+	hash := sha256.New()
+	for _, page := range pages {
+		hash.Write([]byte(page.Fingerprint() + ";"))
+	}
+	fingerprint := hex.EncodeToString(hash.Sum(nil)) + ":" + timeBucket()
+
+	return s.cached(c, "index", fingerprint, func() (xmlDocument, error) {
+		now := time.Now()
+		index := response.SitemapIndex{
+			Sitemaps: []response.SitemapRef{
+				{Loc: config.AppURL + "/sitemaps/news.xml", LastMod: now.Format(time.RFC3339)},
+				{Loc: config.AppURL + "/sitemaps/categories.xml"},
+			},
+		}
+		modified := time.Unix(0, 0)
+		for _, page := range pages {
+			index.Sitemaps = append(index.Sitemaps, response.SitemapRef{
+				Loc:     fmt.Sprintf("%s/sitemaps/articles-%d.xml", config.AppURL, page.Number),
+				LastMod: page.LastMod.Format(time.RFC3339),
+			})
+			if page.LastMod.After(modified) {
+				modified = page.LastMod
+			}
+		}
+		return newXMLDocument(index, modified)
+	})
+}
+
+func (s *SitemapController) Articles(c *fiber.Ctx) error {
+	number, err := strconv.Atoi(c.Params("page"))
+	if err != nil || number < 1 {
+		return fiber.ErrNotFound
+	}
+
+	pages, err := s._SitemapService.ArticlePages()
+	if err != nil {
+		return serviceError(c, err, "could not build sitemap")
+	}
+	if number > len(pages) {
+		return fiber.ErrNotFound
+	}
+	page := pages[number-1]
+
+	return s.cached(c, "articles-"+strconv.Itoa(number), page.Fingerprint(), func() (xmlDocument, error) {
+		items, err := s._SitemapService.PageArticles(page)
+		if err != nil {
+			return xmlDocument{}, err
+		}
+
+		set := response.URLSet{ImageNS: "http://www.google.com/schemas/sitemap-image/1.1"}
+		for i := range items {
+			a := &items[i]
+			u := response.SitemapURL{Loc: service.ArticleURL(a), LastMod: a.UpdatedAt.Format(time.RFC3339)}
+			if a.Image != nil && *a.Image != "" {
+				u.Image = &response.SitemapImage{Loc: service.AbsoluteURL(*a.Image)}
+			}
+			set.URLs = append(set.URLs, u)
+		}
+		return newXMLDocument(set, page.LastMod)
+	})
+}
+
+func (s *SitemapController) News(c *fiber.Ctx) error {
+	fingerprint := strconv.FormatInt(service.ArticleVersion(), 10) + ":" + timeBucket()
+
+	return s.cached(c, "news", fingerprint, func() (xmlDocument, error) {
+		items, err := s._SitemapService.NewsArticles()
+		if err != nil {
+			return xmlDocument{}, err
+		}
+
+		set := response.URLSet{NewsNS: "http://www.google.com/schemas/sitemap-news/0.9"}
+		modified := time.Unix(0, 0)
+		for i := range items {
+			a := &items[i]
+			set.URLs = append(set.URLs, response.SitemapURL{
+				Loc: service.ArticleURL(a),
+				News: &response.SitemapNews{
+					Publication:     response.SitemapPublication{Name: config.SiteName, Language: "bn"},
+					PublicationDate: publishedAt(a).Format(time.RFC3339),
+					Title:           a.Title,
+				},
+			})
+			if a.UpdatedAt.After(modified) {
+				modified = a.UpdatedAt
+			}
+		}
+		return newXMLDocument(set, modified)
+	})
+}
+
+func (s *SitemapController) Categories(c *fiber.Ctx) error {
+	fingerprint := strconv.FormatInt(service.ArticleVersion(), 10) + ":" + timeBucket()
+
+	return s.cached(c, "categories", fingerprint, func() (xmlDocument, error) {
+		items, err := s._SitemapService.Categories()
+		if err != nil {
+			return xmlDocument{}, err
+		}
+
+		set := response.URLSet{URLs: []response.SitemapURL{{Loc: config.AppURL + "/"}}}
+		modified := time.Unix(0, 0)
+		for i := range items {
+			lastMod := items[i].UpdatedAt
+			if items[i].LastMod != nil && items[i].LastMod.After(lastMod) {
+				lastMod = *items[i].LastMod
+			}
+			set.URLs = append(set.URLs, response.SitemapURL{
+				Loc:     service.CategoryURL(&items[i].Category),
+				LastMod: lastMod.Format(time.RFC3339),
+			})
+			if lastMod.After(modified) {
+				modified = lastMod
+			}
+		}
+		return newXMLDocument(set, modified)
+	})
+}
+
+// Robots points crawlers at the sitemap index.
+func (s *SitemapController) Robots(c *fiber.Ctx) error {
+	lines := []string{
+		"User-agent: *",
+		"Disallow: /admin",
+		"Disallow: /dashboard",
+		"Disallow: /login",
+		"Sitemap: " + config.AppURL + "/sitemap.xml",
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(strings.Join(lines, "\n") + "\n")
+}
+
+// publishedAt is when readers first saw the article.
+func publishedAt(a *model.Article) time.Time {
+	if a.PublishAt != nil {
+		return *a.PublishAt
+	}
+	return a.Created
+}
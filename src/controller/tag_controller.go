@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TagController struct {
+	_TagService service.TagService
+}
+
+func NewTagController(s service.TagService) *TagController {
+	return &TagController{_TagService: s}
+}
+
+// Get All with article counts
+func (t *TagController) GetAll(c *fiber.Ctx) error {
+	query := &validation.QueryTag{
+		Page:   c.QueryInt("page", 1),
+		Limit:  c.QueryInt("limit", 50),
+		Search: c.Query("search"),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, total, err := t._TagService.GetAll(query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
+			Code:    fiber.StatusInternalServerError,
+			Status:  "error",
+			Message: "could not fetch tags",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.TagCount]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      "Get all tags successfully",
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
+}
+
+// Suggest for auto-complete while editing
+func (t *TagController) Suggest(c *fiber.Ctx) error {
+	items, err := t._TagService.Suggest(c.Query("q"), c.QueryInt("limit", 10))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.ErrorDetails{
+			Code:    fiber.StatusInternalServerError,
+			Status:  "error",
+			Message: "could not fetch tag suggestions",
+			Errors:  err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// Topic page
+func (t *TagController) GetArticles(c *fiber.Ctx) error {
+	query := &validation.QueryTag{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 20),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	// Bengali slugs arrive percent-encoded
+	slug, err := url.PathUnescape(c.Params("slug"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(response.ErrorDetails{
+			Code:    fiber.StatusNotFound,
+			Status:  "fail",
+			Message: "tag not found",
+			Errors:  err.Error(),
+		})
+	}
+
+	tag, items, total, err := t._TagService.GetArticles(slug, query)
+	if err != nil {
+		return serviceError(c, err, "could not fetch tag articles")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.SuccessWithPaginate[model.Article]{
+		Code:         fiber.StatusOK,
+		Status:       "success",
+		Message:      tag.Name,
+		Results:      items,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		TotalPages:   totalPages(total, query.Limit),
+		TotalResults: total,
+	})
+}
+
+// Rename
+func (t *TagController) Rename(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("tagId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	req := new(validation.RenameTag)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	tag, err := t._TagService.Rename(id, req)
+	if err != nil {
+		return serviceError(c, err, "could not rename tag")
+	}
+	return c.Status(fiber.StatusOK).JSON(tag)
+}
+
+// Merge
+func (t *TagController) Merge(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("tagId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid id",
+			Errors:  err.Error(),
+		})
+	}
+
+	req := new(validation.MergeTag)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	tag, err := t._TagService.Merge(id, req)
+	if err != nil {
+		return serviceError(c, err, "could not merge tags")
+	}
+	return c.Status(fiber.StatusOK).JSON(tag)
+}
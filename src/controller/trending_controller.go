@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrendingController struct {
+	_TrendingService service.TrendingService
+}
+
+func NewTrendingController(s service.TrendingService) *TrendingController {
+	return &TrendingController{_TrendingService: s}
+}
+
+func (t *TrendingController) GetTrending(c *fiber.Ctx) error {
+	query := &validation.QueryTrending{
+		Category: c.Query("category"),
+		Limit:    c.QueryInt("limit", 10),
+	}
+
+	if err := validation.Validator().Struct(query); err != nil {
+		return err
+	}
+
+	items, err := t._TrendingService.GetTrending(query.Category, query.Limit)
+	if err != nil {
+		return serviceError(c, err, "could not fetch trending articles")
+	}
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+func (t *TrendingController) Share(c *fiber.Ctx) error {
+	req := new(validation.ShareArticle)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "invalid json",
+			Errors:  err.Error(),
+		})
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	if err := t._TrendingService.RecordShare(req.ID, req.Network, c.IP(), c.Get(fiber.HeaderUserAgent)); err != nil {
+		return serviceError(c, err, "could not record share")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Common{
+		Code:    fiber.StatusOK,
+		Status:  "success",
+		Message: "Share recorded",
+	})
+}
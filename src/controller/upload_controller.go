@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/response"
+	"app/src/service"
+	"app/src/validation"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The tus protocol version spoken, with the extensions supported:
+// https://tus.io/protocols/resumable-upload
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+	tusChunkType  = "application/offset+octet-stream"
+)
+
+// UploadController takes resumable uploads over the tus protocol, for video
+// and large files sent from poor connections: a client creates an upload,
+// sends it in chunks with PATCH, asks with HEAD how much arrived after a
+// dropped connection and carries on from there.
+type UploadController struct {
+	_UploadService service.UploadService
+}
+
+func NewUploadController(s service.UploadService) *UploadController {
+	return &UploadController{_UploadService: s}
+}
+
+// Resumable answers every tus request with the protocol version and refuses
+// clients speaking another one.
+func (u *UploadController) Resumable(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	if c.Method() == fiber.MethodOptions {
+		return c.Next()
+	}
+	if version := c.Get("Tus-Resumable"); version != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(response.ErrorDetails{
+			Code:    fiber.StatusPreconditionFailed,
+			Status:  "fail",
+			Message: "only tus " + tusVersion + " is supported",
+			Errors:  version,
+		})
+	}
+	return c.Next()
+}
+
+// Options tells clients what the server supports
+func (u *UploadController) Options(c *fiber.Ctx) error {
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(config.TusMaxBytes, 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Create starts an upload of Upload-Length bytes, described by
+// Upload-Metadata, and points to it with Location
+func (u *UploadController) Create(c *fiber.Ctx) error {
+	if c.Get("Upload-Defer-Length") != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "the length of an upload must be known when it is created",
+		})
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "Upload-Length is required",
+			Errors:  err.Error(),
+		})
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	upload, err := u._UploadService.Create(length, c.Get("Upload-Metadata"), actor)
+	if err != nil {
+		return serviceError(c, err, "could not create upload")
+	}
+
+	c.Set(fiber.HeaderLocation, strings.TrimSuffix(c.Path(), "/")+"/"+upload.ID)
+	setUploadExpires(c, upload)
+	return c.Status(fiber.StatusCreated).JSON(upload)
+}
+
+// Head reports how many bytes of an upload have arrived, for a client to
+// resume from
+func (u *UploadController) Head(c *fiber.Ctx) error {
+	actor, _ := c.Locals("user").(*model.User)
+	upload, err := u._UploadService.Get(c.Params("uploadId"), actor)
+	if err != nil {
+		return serviceError(c, err, "could not fetch upload")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.Metadata != "" {
+		c.Set("Upload-Metadata", upload.Metadata)
+	}
+	setUploadExpires(c, upload)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Patch takes the chunk starting at Upload-Offset. The last chunk stores the
+// finished file in the media library; one that is refused fails the upload
+// with the reason.
+func (u *UploadController) Patch(c *fiber.Ctx) error {
+	if c.Get(fiber.HeaderContentType) != tusChunkType {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(response.ErrorDetails{
+			Code:    fiber.StatusUnsupportedMediaType,
+			Status:  "fail",
+			Message: "chunks are sent as " + tusChunkType,
+		})
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(response.ErrorDetails{
+			Code:    fiber.StatusBadRequest,
+			Status:  "fail",
+			Message: "Upload-Offset is required",
+		})
+	}
+	chunk := c.Body()
+	if int64(len(chunk)) > config.TusChunkMaxBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(response.ErrorDetails{
+			Code:    fiber.StatusRequestEntityTooLarge,
+			Status:  "fail",
+			Message: "chunks may be at most " + strconv.FormatInt(config.TusChunkMaxBytes, 10) + " bytes",
+		})
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	upload, err := u._UploadService.Write(c.Params("uploadId"), offset, chunk, actor)
+	if err != nil {
+		return serviceError(c, err, "could not store chunk")
+	}
+
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	setUploadExpires(c, upload)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Terminate cancels an upload and removes what was received
+func (u *UploadController) Terminate(c *fiber.Ctx) error {
+	actor, _ := c.Locals("user").(*model.User)
+	if err := u._UploadService.Terminate(c.Params("uploadId"), actor); err != nil {
+		return serviceError(c, err, "could not cancel upload")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Get reports an upload as JSON, with its media once it is complete
+func (u *UploadController) Get(c *fiber.Ctx) error {
+	actor, _ := c.Locals("user").(*model.User)
+	upload, err := u._UploadService.Get(c.Params("uploadId"), actor)
+	if err != nil {
+		return serviceError(c, err, "could not fetch upload")
+	}
+	return c.Status(fiber.StatusOK).JSON(upload)
+}
+
+// Attach puts a complete upload on an article: a photo as its main image or
+// in its body, a video in its body
+func (u *UploadController) Attach(c *fiber.Ctx) error {
+	req := new(validation.AttachUpload)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	article, err := u._UploadService.Attach(c.Params("uploadId"), req, actor)
+	if err != nil {
+		var stale *service.StaleArticleError
+		if errors.As(err, &stale) {
+			c.Set(fiber.HeaderETag, articleETag(stale.Current))
+			return c.Status(fiber.StatusPreconditionFailed).JSON(response.ErrorDetails{
+				Code:    fiber.StatusPreconditionFailed,
+				Status:  "fail",
+				Message: "article was changed by someone else",
+				Errors:  stale.Current,
+			})
+		}
+
+		return serviceError(c, err, "could not attach upload")
+	}
+
+	c.Set(fiber.HeaderETag, articleETag(article))
+	return c.Status(fiber.StatusOK).JSON(article)
+}
+
+func setUploadExpires(c *fiber.Ctx, upload *model.ResumableUpload) {
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"app/src/model"
 	"app/src/response"
 	"app/src/service"
 	"app/src/validation"
@@ -99,6 +100,25 @@ func (u *UserController) UpdateUser(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(user)
 }
 
+// SetRole gives a user a role, and with it the rights in config.RoleRights
+func (u *UserController) SetRole(c *fiber.Ctx) error {
+	req := new(validation.UpdateRole)
+	if err := c.BodyParser(req); err != nil {
+		return invalidJSON(c, err)
+	}
+
+	if err := validation.Validator().Struct(req); err != nil {
+		return err
+	}
+
+	actor, _ := c.Locals("user").(*model.User)
+	user, err := u._UserService.SetRole(c, c.Params("userId"), req.Role, actor)
+	if err != nil {
+		return serviceError(c, err, "could not change role")
+	}
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
 // delete
 func (u *UserController) DeleteUser(c *fiber.Ctx) error {
 	userID := c.Params("userId")
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// xmlDocument is a rendered feed or sitemap with its validators.
+type xmlDocument struct {
+	body     []byte
+	etag     string
+	modified time.Time
+	built    time.Time
+}
+
+func newXMLDocument(doc interface{}, modified time.Time) (xmlDocument, error) {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return xmlDocument{}, err
+	}
+	body = append([]byte(xml.Header), body...)
+
+	sum := sha256.Sum256(body)
+	return xmlDocument{
+		body:     body,
+		etag:     `"` + hex.EncodeToString(sum[:8]) + `"`,
+		modified: modified.Truncate(time.Second),
+		built:    time.Now(),
+	}, nil
+}
+
+// sendXML honours If-None-Match and If-Modified-Since, so polling feed
+// readers and crawlers mostly get a 304.
+func sendXML(c *fiber.Ctx, doc xmlDocument, contentType string) error {
+	c.Set(fiber.HeaderETag, doc.etag)
+	c.Set(fiber.HeaderLastModified, doc.modified.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	if c.Fresh() {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(doc.body)
+}
@@ -82,9 +82,31 @@ func Connect(dbHost, dbName string) *gorm.DB {
 	sqlDB.SetConnMaxIdleTime(5 * time.Minute)   // Close idle connections after 5 min
 
 	// Run Migrations
-	if err := db.AutoMigrate(&model.Article{}, &model.User{}); err != nil {
+	if err := db.AutoMigrate(
+		&model.Article{},
+		&model.User{},
+		&model.ArticleTransition{},
+		&model.ArticleRevision{},
+		&model.Category{},
+		&model.Tag{},
+		&model.ArticleSlug{},
+		&model.ArticleViewStat{},
+		&model.ArticleVisit{},
+		&model.ArticleShareStat{},
+		&model.ArticleSharer{},
+		&model.TrendingEntry{},
+		&model.StreamEvent{},
+		&model.BreakingNews{},
+		&model.LiveBlog{},
+		&model.LiveBlogEntry{},
+		&model.Media{},
+		&model.MediaVariant{},
+		&model.ResumableUpload{},
+	); err != nil {
 		utils.Log.Errorf("Failed to auto migrate: %+v", err)
 	}
+	migrateArticles(db)
+	migrateUsers(db)
 
 	// Store global reference
 	globalDB = db
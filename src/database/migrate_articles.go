@@ -2,9 +2,165 @@ package database
 
 import (
 	"app/src/model"
+	"app/src/utils"
+	"fmt"
+	"strings"
+
 	"gorm.io/gorm"
 )
 
 func autoMigrateCMS(db *gorm.DB) {
 	_ = db.AutoMigrate(&model.Article{})
 }
+
+// defaultCategories is the list previously hardcoded in ArticleService.
+var defaultCategories = []model.Category{
+	{Slug: "national", NameBn: "জাতীয়", NameEn: "National", SortOrder: 1},
+	{Slug: "politics", NameBn: "রাজনীতি", NameEn: "Politics", SortOrder: 2},
+	{Slug: "sports", NameBn: "খেলাধুলা", NameEn: "Sports", SortOrder: 3},
+	{Slug: "technology", NameBn: "প্রযুক্তি", NameEn: "Technology", SortOrder: 4},
+	{Slug: "health", NameBn: "স্বাস্থ্য", NameEn: "Health", SortOrder: 5},
+	{Slug: "entertainment", NameBn: "বিনোদন", NameEn: "Entertainment", SortOrder: 6},
+	{Slug: "international", NameBn: "আন্তর্জাতিক", NameEn: "International", SortOrder: 7},
+	{Slug: "others", NameBn: "অন্যান্য", NameEn: "Others", SortOrder: 8},
+}
+
+// migrateArticles runs the data backfills that AutoMigrate cannot express.
+// Each step is idempotent and safe to run on every start.
+func migrateArticles(db *gorm.DB) {
+	migrateCategories(db)
+	migrateSlugs(db)
+	migrateSearch(db)
+
+	// Articles written before revision history existed get their current
+	// state recorded as revision 1, so the first edit stays reversible.
+	err := db.Exec(`
+		INSERT INTO article_revisions (article_id, revision, title, content, category, image, featured, editor_name, created_at)
+		SELECT a.id, 1, a.title, a.content, a.category, a.image, a.featured, a.author, COALESCE(a.updated_at, now())
+		FROM articles a
+		WHERE NOT EXISTS (SELECT 1 FROM article_revisions r WHERE r.article_id = a.id)`).Error
+	if err != nil {
+		utils.Log.Errorf("Failed to backfill article revisions: %+v", err)
+	}
+}
+
+// migrateCategories seeds the category table and links existing articles to
+// it by their free-text category name. Names that match no category (typos,
+// retired sections) get a category of their own so nothing is orphaned.
+func migrateCategories(db *gorm.DB) {
+	var count int64
+	if err := db.Model(&model.Category{}).Count(&count).Error; err != nil {
+		utils.Log.Errorf("Failed to count categories: %+v", err)
+		return
+	}
+	if count == 0 {
+		if err := db.Create(&defaultCategories).Error; err != nil {
+			utils.Log.Errorf("Failed to seed categories: %+v", err)
+			return
+		}
+	}
+
+	var unknown []string
+	err := db.Model(&model.Article{}).
+		Distinct("category").
+		Where("category_id IS NULL AND category <> ''").
+		Where("category NOT IN (?)", db.Model(&model.Category{}).Select("name_bn")).
+		Pluck("category", &unknown).Error
+	if err != nil {
+		utils.Log.Errorf("Failed to find unmapped article categories: %+v", err)
+		return
+	}
+
+	for _, name := range unknown {
+		cat := model.Category{Slug: utils.Slugify(name), NameBn: name, SortOrder: 100}
+		if err := db.Where(model.Category{NameBn: name}).FirstOrCreate(&cat).Error; err != nil {
+			utils.Log.Errorf("Failed to create category %q: %+v", name, err)
+		}
+	}
+
+	err = db.Exec(`
+		UPDATE articles SET category_id = c.id
+		FROM categories c
+		WHERE articles.category_id IS NULL AND articles.category = c.name_bn`).Error
+	if err != nil {
+		utils.Log.Errorf("Failed to link articles to categories: %+v", err)
+	}
+}
+
+// migrateSlugs gives every article that has none a slug derived from its
+// title, suffixed with the ID where two titles transliterate alike.
+func migrateSlugs(db *gorm.DB) {
+	var articles []model.Article
+	if err := db.Select("id", "title").Where("slug IS NULL OR slug = ''").Order("id").Find(&articles).Error; err != nil {
+		utils.Log.Errorf("Failed to load articles without slug: %+v", err)
+		return
+	}
+	if len(articles) == 0 {
+		return
+	}
+
+	var existing []string
+	if err := db.Model(&model.Article{}).Where("slug IS NOT NULL").Pluck("slug", &existing).Error; err != nil {
+		utils.Log.Errorf("Failed to load article slugs: %+v", err)
+		return
+	}
+	used := make(map[string]bool, len(existing))
+	for _, slug := range existing {
+		used[slug] = true
+	}
+
+	for _, a := range articles {
+		slug := utils.ArticleSlug(a.Title)
+		if used[slug] {
+			slug = fmt.Sprintf("%s-%d", slug, a.ID)
+		}
+		used[slug] = true
+
+		if err := db.Model(&model.Article{}).Where("id = ?", a.ID).UpdateColumn("slug", slug).Error; err != nil {
+			utils.Log.Errorf("Failed to set slug for article %d: %+v", a.ID, err)
+		}
+	}
+}
+
+// migrateSearch maintains the weighted full-text column behind /search. The
+// 'simple' configuration does no stemming, which suits Bengali; text is kept
+// in NFC and zero-width joiners are ignored, matching utils.SearchText. The
+// content is indexed without its HTML tags, so words like "strong" or "href"
+// do not match every article. A column built before tags were stripped is
+// rebuilt.
+func migrateSearch(db *gorm.DB) {
+	var expression string
+	err := db.Raw(`
+		SELECT pg_get_expr(d.adbin, d.adrelid)
+		FROM pg_attrdef d
+		JOIN pg_attribute a ON a.attrelid = d.adrelid AND a.attnum = d.adnum
+		WHERE a.attrelid = 'articles'::regclass AND a.attname = 'search_vector'`).Scan(&expression).Error
+	if err != nil {
+		utils.Log.Errorf("Failed to inspect article search column: %+v", err)
+		return
+	}
+
+	steps := []string{
+		`UPDATE articles SET title = normalize(title, NFC), content = normalize(content, NFC)
+		 WHERE title IS NOT NFC NORMALIZED OR content IS NOT NFC NORMALIZED`,
+	}
+	if expression != "" && !strings.Contains(expression, "regexp_replace") {
+		steps = append(steps, `ALTER TABLE articles DROP COLUMN search_vector`)
+	}
+	steps = append(steps,
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS search_vector tsvector
+		 GENERATED ALWAYS AS (
+		     setweight(to_tsvector('simple', translate(coalesce(title, ''), E'\u200C\u200D', '')), 'A') ||
+		     setweight(to_tsvector('simple', translate(
+		         regexp_replace(coalesce(content, ''), '<[^>]+>', ' ', 'g'), E'\u200C\u200D', '')), 'B')
+		 ) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_search_vector ON articles USING GIN (search_vector)`,
+	)
+
+	for _, step := range steps {
+		if err := db.Exec(step).Error; err != nil {
+			utils.Log.Errorf("Failed to migrate article search: %+v", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+
+	"gorm.io/gorm"
+)
+
+// developmentAdminID is the account the development login (admin/admin)
+// signs in as.
+const developmentAdminID = 1
+
+// migrateUsers makes ADMIN_EMAIL an admin, and in development the
+// development admin too. Accounts are created with the "user" role, which
+// grants no editorial rights, so without this nobody could publish or
+// give out roles.
+func migrateUsers(db *gorm.DB) {
+	if config.AdminEmail != "" {
+		result := db.Model(&model.User{}).
+			Where("email = ? AND role <> ?", config.AdminEmail, "admin").
+			Update("role", "admin")
+		if result.Error != nil {
+			utils.Log.Errorf("Failed to make %s an admin: %+v", config.AdminEmail, result.Error)
+		} else if result.RowsAffected > 0 {
+			utils.Log.Infof("%s is now an admin", config.AdminEmail)
+		}
+	}
+
+	if config.IsDev {
+		err := db.Model(&model.User{}).
+			Where("id = ? AND role <> ?", developmentAdminID, "admin").
+			Update("role", "admin").Error
+		if err != nil {
+			utils.Log.Errorf("Failed to make the development admin an admin: %+v", err)
+		}
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"app/src/database"
 	"app/src/middleware"
 	"app/src/router"
+	"app/src/service"
+	"app/src/storage"
 	"app/src/utils"
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
@@ -30,32 +35,48 @@ import (
 // @name Authorization
 // @description Example Value: Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "storage-migrate" {
+		os.Exit(migrateStorage(context.Background(), os.Args[2:]))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	app := setupFiberApp()
+	files := setupStorage()
+	app := setupFiberApp(files)
 	db := setupDatabase()
 	defer closeDatabase(db)
-	setupRoutes(app, db)
+	views := service.NewViewService(db, config.ViewDedupeWindow)
+	// Write buffered views before the process exits
+	app.Hooks().OnShutdown(func() error {
+		return views.Flush(context.Background())
+	})
+	events := service.NewEventBroker(db, config.StreamRetention)
+	images := service.NewImageService(db, files)
+	setupRoutes(app, db, views, events, images, files)
+	startBackgroundJobs(ctx, db, views, events, images, files)
 
 	address := fmt.Sprintf("%s:%d", config.AppHost, config.AppPort)
 
 	// Start server and handle graceful shutdown
 	serverErrors := make(chan error, 1)
 	go startServer(app, address, serverErrors)
-	handleGracefulShutdown(ctx, app, serverErrors)
+	handleGracefulShutdown(ctx, cancel, app, serverErrors)
 }
 
-func setupFiberApp() *fiber.App {
+func setupFiberApp(files storage.Storage) *fiber.App {
 	app := fiber.New(config.FiberConfig())
 
-	router.FrontendRoutes(app)
+	router.FrontendRoutes(app, files)
 
 	// Middleware setup
 	app.Use("/v1/auth", middleware.LimiterConfig())
 	app.Use(middleware.LoggerConfig())
 	app.Use(helmet.New())
-	app.Use(compress.New())
+	app.Use(compress.New(compress.Config{
+		// Event streams are flushed event by event
+		Next: func(c *fiber.Ctx) bool { return strings.HasSuffix(c.Path(), "/stream") },
+	}))
 	app.Use(cors.New())
 	app.Use(middleware.RecoverConfig())
 
@@ -68,11 +89,89 @@ func setupDatabase() *gorm.DB {
 	return db
 }
 
-func setupRoutes(app *fiber.App, db *gorm.DB) {
-	router.Routes(app, db)
+func setupStorage() storage.Storage {
+	files, err := storage.New(config.StorageDriver)
+	if err != nil {
+		utils.Log.Errorf("Failed to open upload storage: %+v", err)
+		panic("Upload storage failed - cannot start application")
+	}
+	return files
+}
+
+func setupRoutes(app *fiber.App, db *gorm.DB, views service.ViewService, events service.EventBroker, images service.ImageService, files storage.Storage) {
+	router.Routes(app, db, views, events, images, files)
 	app.Use(utils.NotFoundHandler)
 }
 
+func startBackgroundJobs(ctx context.Context, db *gorm.DB, views service.ViewService, events service.EventBroker, images service.ImageService, files storage.Storage) {
+	// Every process keeps its own response caches, so every process watches
+	go service.WatchArticleChanges(ctx, db, 5*time.Second)
+
+	// ...and buffers its own views; see main for the flush on shutdown
+	go views.Run(ctx, config.ViewFlushInterval)
+
+	// ...and serves its own event streams
+	go events.Run(ctx, config.StreamPollInterval)
+
+	// ...and makes image variants, waking at once for its own uploads
+	go images.Run(ctx, 5*time.Second)
+
+	// Prefork children would only duplicate the parent's work
+	if !fiber.IsChild() {
+		go service.NewArticleScheduler(db).Run(ctx, 15*time.Second)
+		go service.NewTrendingService(db).Run(ctx, config.TrendingInterval)
+		go service.NewBreakingNewsService(db).Run(ctx, time.Second)
+		go service.NewMediaService(db, files).Run(ctx, 24*time.Hour)
+		go service.NewUploadService(db, files, images).Run(ctx, time.Hour)
+	}
+}
+
+// migrateStorage moves uploads between backends:
+//
+//	main storage-migrate -from local -to s3 [-remove] [-dry-run]
+//
+// Both backends are configured as usual; STORAGE_DRIVER is not consulted.
+// Links to uploads do not change, so switch STORAGE_DRIVER once it is done.
+func migrateStorage(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("storage-migrate", flag.ContinueOnError)
+	from := flags.String("from", storage.Local, "backend to copy from: local or s3")
+	to := flags.String("to", storage.S3, "backend to copy to: local or s3")
+	remove := flags.Bool("remove", false, "delete each file from the source once it is copied")
+	dryRun := flags.Bool("dry-run", false, "only report what would be copied")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *from == *to {
+		utils.Log.Errorf("Storage migration: -from and -to are both %s", *from)
+		return 2
+	}
+
+	source, err := storage.New(*from)
+	if err != nil {
+		utils.Log.Errorf("Storage migration: %+v", err)
+		return 1
+	}
+	target, err := storage.New(*to)
+	if err != nil {
+		utils.Log.Errorf("Storage migration: %+v", err)
+		return 1
+	}
+
+	result, err := storage.Migrate(ctx, source, target, *remove, *dryRun, func(key string, err error) {
+		utils.Log.Errorf("Storage migration: %s: %+v", key, err)
+	})
+	utils.Log.Infof("Storage migration from %s to %s: %d copied, %d already there, %d removed, %d failed",
+		*from, *to, result.Copied, result.Skipped, result.Removed, result.Failed)
+	if err != nil {
+		utils.Log.Errorf("Storage migration stopped: %+v", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
 func startServer(app *fiber.App, address string, errs chan<- error) {
 	if err := app.Listen(address); err != nil {
 		errs <- fmt.Errorf("error starting server: %w", err)
@@ -93,7 +192,7 @@ func closeDatabase(db *gorm.DB) {
 	}
 }
 
-func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-chan error) {
+func handleGracefulShutdown(ctx context.Context, cancel context.CancelFunc, app *fiber.App, serverErrors <-chan error) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
@@ -102,6 +201,9 @@ func handleGracefulShutdown(ctx context.Context, app *fiber.App, serverErrors <-
 		utils.Log.Fatalf("Server error: %v", err)
 	case <-quit:
 		utils.Log.Info("Shutting down server...")
+		// Stop background jobs first; this also ends open event streams,
+		// which Shutdown would otherwise wait for
+		cancel()
 		if err := app.Shutdown(); err != nil {
 			utils.Log.Fatalf("Error during server shutdown: %v", err)
 		}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"app/src/config"
+	"app/src/service"
+	"app/src/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Auth resolves the caller from the Authorization header (or the auth_token
+// cookie set by the login page), stores the user in c.Locals("user") and
+// checks the role against requiredRights.
+func Auth(userService service.UserService, requiredRights ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokens := []string{
+			strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")),
+			c.Cookies("auth_token"),
+		}
+
+		userID := ""
+		for _, token := range tokens {
+			if token == "" {
+				continue
+			}
+			if id, err := utils.VerifyToken(token, config.JWTSecret, config.TokenTypeAccess); err == nil {
+				userID = id
+				break
+			}
+		}
+
+		if userID == "" {
+			return fiber.NewError(fiber.StatusUnauthorized, "Please authenticate")
+		}
+
+		user, err := userService.GetByUserId(c, userID)
+		if err != nil || user == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "Please authenticate")
+		}
+
+		if user.Status != "active" {
+			return fiber.NewError(fiber.StatusForbidden, "Account is not active")
+		}
+
+		c.Locals("user", user)
+
+		if len(requiredRights) > 0 && !config.HasRights(user.Role, requiredRights...) {
+			return fiber.NewError(fiber.StatusForbidden, "You don't have permission to access this resource")
+		}
+
+		return c.Next()
+	}
+}
@@ -23,3 +23,20 @@ func LimiterConfig() fiber.Handler {
 		SkipSuccessfulRequests: true,
 	})
 }
+
+// ShareLimiter caps how many shares one address can report, since anyone
+// can report them.
+func ShareLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        20,
+		Expiration: time.Minute,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).
+				JSON(response.Common{
+					Code:    fiber.StatusTooManyRequests,
+					Status:  "error",
+					Message: "খুব বেশি রিকোয়েস্ট পাঠানো হয়েছে, দয়া করে একটু পর চেষ্টা করুন।",
+				})
+		},
+	})
+}
@@ -1,17 +1,51 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Editorial workflow states. Only published articles are visible to readers.
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusReview    = "review"
+	ArticleStatusApproved  = "approved"
+	ArticleStatusScheduled = "scheduled"
+	ArticleStatusPublished = "published"
+	ArticleStatusArchived  = "archived"
+)
 
 type Article struct {
-	ID       int       `gorm:"primaryKey" json:"id"`
-	Title    string    `json:"title" gorm:"type:text;not null"`
-	Content  string    `json:"content" gorm:"type:text;not null"`
-	Category string    `json:"category" gorm:"type:varchar(255);not null;index"`
-	Author   string    `json:"author" gorm:"type:varchar(255);not null"`
-	Image    *string   `json:"image"`
-	Created   time.Time `json:"created" gorm:"column:created_at;index"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime;index"`
-	Featured  bool      `json:"featured" gorm:"default:false;index"`
+	ID         int       `gorm:"primaryKey" json:"id"`
+	Slug       string    `json:"slug" gorm:"type:varchar(200);uniqueIndex;default:null"`
+	Title      string    `json:"title" gorm:"type:text;not null"`
+	Content    string    `json:"content" gorm:"type:text;not null"`
+	Category   string    `json:"category" gorm:"type:varchar(255);not null;index"` // Bengali name, kept in sync with CategoryID
+	CategoryID *int      `json:"category_id" gorm:"index"`
+	Author     string    `json:"author" gorm:"type:varchar(255);not null"`
+	Image      *string   `json:"image"`
+	Created    time.Time `json:"created" gorm:"column:created_at;index"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime;index"`
+	Featured   bool      `json:"featured" gorm:"default:false;index"`
+	// The column default only backfills rows that existed before the workflow;
+	// ArticleService creates new articles as drafts.
+	Status string `json:"status" gorm:"type:varchar(20);not null;default:'published';index"`
+	// Embargo window: readers only see the article between PublishAt and ExpireAt
+	PublishAt *time.Time `json:"publish_at,omitempty" gorm:"index"`
+	ExpireAt  *time.Time `json:"expire_at,omitempty" gorm:"index"`
+	// Version increases with every content change and backs the ETag used for
+	// optimistic concurrency control on updates
+	Version int `json:"version" gorm:"not null;default:1"`
+	// Lifetime reader views, flushed in batches by ViewService
+	ViewCount int64 `json:"view_count" gorm:"not null;default:0"`
+	// Deleted articles stay in the trash until restored or purged
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	DeletedBy *int           `json:"deleted_by,omitempty"`
+	// Sizes of Image for responsive markup, when it was uploaded here
+	Images *ImageSet `json:"images,omitempty" gorm:"-"`
+
+	Tags []Tag `json:"tags,omitempty" gorm:"many2many:article_tags"`
 }
 
 type Response struct {
@@ -21,6 +55,7 @@ type Response struct {
 }
 
 type ImageUploadResponse struct {
-	Message string `json:"message"`
-	URL     string `json:"url"`
+	Message string         `json:"message"`
+	URL     string         `json:"url"`
+	Image   *UploadedImage `json:"image,omitempty"`
 }
@@ -0,0 +1,29 @@
+package model
+
+// Outcomes of one article in a bulk operation.
+const (
+	BulkChanged   = "changed"
+	BulkUnchanged = "unchanged"
+	BulkFailed    = "failed"
+)
+
+// BulkItemResult is what a bulk operation did, or would do, to one article.
+// Changes maps each changed field to its old and new value.
+type BulkItemResult struct {
+	ID      int                       `json:"id"`
+	Result  string                    `json:"result"`
+	Error   string                    `json:"error,omitempty"`
+	Changes map[string][2]interface{} `json:"changes,omitempty"`
+}
+
+// BulkResult reports a bulk operation. Applied is false for a dry run and
+// when any article failed, as the operation is all or nothing.
+type BulkResult struct {
+	Operation string           `json:"operation"`
+	DryRun    bool             `json:"dry_run"`
+	Applied   bool             `json:"applied"`
+	Changed   int              `json:"changed"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
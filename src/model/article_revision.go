@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ArticleRevision is an immutable snapshot of an article, written on every
+// create, update and restore.
+type ArticleRevision struct {
+	ID           int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleID    int       `gorm:"not null;uniqueIndex:idx_article_revision" json:"article_id"`
+	Revision     int       `gorm:"not null;uniqueIndex:idx_article_revision" json:"revision"`
+	Title        string    `gorm:"type:text;not null" json:"title"`
+	Content      string    `gorm:"type:text;not null" json:"content,omitempty"`
+	Category     string    `gorm:"type:varchar(255)" json:"category"`
+	Image        *string   `json:"image"`
+	Featured     bool      `gorm:"not null;default:false" json:"featured"`
+	EditorID     *int      `json:"editor_id,omitempty"`
+	EditorName   string    `gorm:"type:varchar(100)" json:"editor_name"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ArticleSlug is a slug an article used to have. Requests for it are
+// redirected permanently to the article's current slug.
+type ArticleSlug struct {
+	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleID int       `gorm:"not null;index" json:"article_id"`
+	Slug      string    `gorm:"type:varchar(200);not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ArticleTransition is one step of an article's editorial history.
+type ArticleTransition struct {
+	ID         int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleID  int       `gorm:"not null;index" json:"article_id"`
+	Action     string    `gorm:"type:varchar(20);not null" json:"action"`
+	FromStatus string    `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus   string    `gorm:"type:varchar(20);not null" json:"to_status"`
+	Note       string    `gorm:"type:text" json:"note,omitempty"`
+	ActorID    *int      `json:"actor_id,omitempty"`
+	ActorName  string    `gorm:"type:varchar(100)" json:"actor_name"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
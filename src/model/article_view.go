@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ArticleViewStat is the number of counted views of an article in one hour.
+type ArticleViewStat struct {
+	ArticleID int       `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	Hour      time.Time `gorm:"primaryKey;index" json:"hour"`
+	Views     int64     `gorm:"not null;default:0" json:"views"`
+}
+
+// ArticleVisit records that a visitor has been counted for an article in one
+// de-duplication window. Rows are pruned once their window has passed.
+type ArticleVisit struct {
+	ArticleID int       `gorm:"primaryKey;autoIncrement:false"`
+	Visitor   string    `gorm:"primaryKey;type:char(32)"`
+	Bucket    int64     `gorm:"primaryKey"` // start of the window, in windows since the epoch
+	ViewedAt  time.Time `gorm:"not null;index"`
+}
+
+// MostReadArticle is an article with its views over the requested period.
+type MostReadArticle struct {
+	Article
+	Views int64 `json:"views"`
+}
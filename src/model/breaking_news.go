@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	BreakingNewsScheduled = "scheduled"
+	BreakingNewsLive      = "live"
+	BreakingNewsExpired   = "expired"
+)
+
+// BreakingNews is a line in the breaking news ticker. It is live from StartAt
+// until EndAt, or until an editor expires it; higher priority comes first.
+type BreakingNews struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	Headline  string     `gorm:"type:varchar(300);not null" json:"headline"`
+	Link      *string    `gorm:"type:varchar(500)" json:"link"`
+	Priority  int        `gorm:"not null;default:0" json:"priority"`
+	Status    string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	StartAt   time.Time  `gorm:"not null" json:"start_at"`
+	EndAt     *time.Time `json:"end_at"`
+	CreatedBy *int       `json:"created_by"`
+	ExpiredBy *int       `json:"expired_by"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
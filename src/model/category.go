@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type Category struct {
+	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Slug      string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug"`
+	NameBn    string    `gorm:"type:varchar(150);not null;uniqueIndex" json:"name_bn"`
+	NameEn    string    `gorm:"type:varchar(150)" json:"name_en"`
+	ParentID  *int      `gorm:"index" json:"parent_id,omitempty"`
+	SortOrder int       `gorm:"not null;default:0" json:"sort_order"`
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
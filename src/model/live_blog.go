@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+const (
+	LiveBlogLive  = "live"
+	LiveBlogEnded = "ended"
+)
+
+// LiveBlog is running coverage of an event, shown under its article. Readers
+// only see it while the article is published.
+type LiveBlog struct {
+	ID        int        `gorm:"primaryKey" json:"id"`
+	ArticleID int        `gorm:"not null;uniqueIndex" json:"article_id"`
+	Title     string     `gorm:"type:varchar(300)" json:"title"`
+	Status    string     `gorm:"type:varchar(20);not null;default:'live'" json:"status"`
+	CreatedBy *int       `json:"created_by"`
+	EndedAt   *time.Time `json:"ended_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Pinned entries, filled in for readers
+	Pinned []LiveBlogEntry `gorm:"-" json:"pinned"`
+}
+
+// LiveBlogEntry is one timestamped update in a live blog.
+type LiveBlogEntry struct {
+	ID         int       `gorm:"primaryKey" json:"id"`
+	LiveBlogID int       `gorm:"not null;index:idx_live_blog_entries_blog_created,priority:1" json:"live_blog_id"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	Image      *string   `gorm:"type:varchar(500)" json:"image"`
+	Author     string    `gorm:"type:varchar(255);not null" json:"author"`
+	AuthorID   *int      `json:"author_id"`
+	Pinned     bool      `gorm:"not null;default:false" json:"pinned"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index:idx_live_blog_entries_blog_created,priority:2" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// LiveBlogSnapshot is a live blog with its latest entries, newest first.
+type LiveBlogSnapshot struct {
+	LiveBlog
+	Entries []LiveBlogEntry `json:"entries"`
+}
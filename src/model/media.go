@@ -0,0 +1,191 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Variant processing states. Skipped media keep only the original, as
+// variants can only be made from JPEG, PNG, GIF and WebP.
+const (
+	MediaPending    = "pending"
+	MediaProcessing = "processing"
+	MediaReady      = "ready"
+	MediaFailed     = "failed"
+	MediaSkipped    = "skipped"
+)
+
+// Variant names. The Open Graph image is cropped to 1200x630 for link
+// previews; the others keep the original's shape.
+const (
+	MediaThumbnail = "thumbnail"
+	MediaCard      = "card"
+	MediaHero      = "hero"
+	MediaOpenGraph = "og"
+)
+
+// Media is an uploaded image, stored under the SHA-256 of its content, with
+// the resized variants made from it in the background. Together they make
+// up the media library, where photos are described once and reused.
+type Media struct {
+	ID     int    `gorm:"primaryKey" json:"id"`
+	File   string `gorm:"type:varchar(100);not null;uniqueIndex" json:"file"`
+	URL    string `gorm:"type:varchar(200);not null;uniqueIndex" json:"url"`
+	Type   string `gorm:"type:varchar(30);not null" json:"type"`
+	Width  int    `gorm:"not null" json:"width"`
+	Height int    `gorm:"not null" json:"height"`
+	Size   int64  `gorm:"not null" json:"size"`
+	Status string `gorm:"type:varchar(20);not null;index" json:"status"`
+	// Why processing failed or was skipped
+	Error string `gorm:"type:text" json:"error,omitempty"`
+
+	// Alternative text, in Bengali, for readers who cannot see the picture
+	Alt     string `gorm:"type:varchar(500)" json:"alt"`
+	Caption string `gorm:"type:text" json:"caption"`
+	// The photographer or agency, shown with the caption
+	Credit       string `gorm:"type:varchar(200);index" json:"credit"`
+	License      string `gorm:"type:varchar(100)" json:"license"`
+	UploadedBy   *int   `gorm:"index" json:"uploaded_by"`
+	UploaderName string `gorm:"type:varchar(100)" json:"uploader_name"`
+
+	Variants  []MediaVariant `gorm:"constraint:OnDelete:CASCADE" json:"variants,omitempty"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;index" json:"updated_at"`
+}
+
+// MediaVariant is one resized copy of a media file, in one format.
+type MediaVariant struct {
+	ID      int    `gorm:"primaryKey" json:"id"`
+	MediaID int    `gorm:"not null;uniqueIndex:idx_media_variant" json:"media_id"`
+	Name    string `gorm:"type:varchar(20);not null;uniqueIndex:idx_media_variant" json:"name"`
+	Format  string `gorm:"type:varchar(10);not null;uniqueIndex:idx_media_variant" json:"format"`
+	File    string `gorm:"type:varchar(120);not null" json:"file"`
+	URL     string `gorm:"type:varchar(220);not null" json:"url"`
+	Width   int    `gorm:"not null" json:"width"`
+	Height  int    `gorm:"not null" json:"height"`
+	Size    int64  `gorm:"not null" json:"size"`
+}
+
+// ImageSet describes an image for responsive markup. Srcset holds, per
+// format ("jpg", "png", "webp"), a srcset value of the variants that keep
+// the original's shape; Variants has every variant by name, then format.
+type ImageSet struct {
+	ID       int                                `json:"id"`
+	Status   string                             `json:"status"`
+	URL      string                             `json:"url"`
+	Width    int                                `json:"width"`
+	Height   int                                `json:"height"`
+	Alt      string                             `json:"alt,omitempty"`
+	Caption  string                             `json:"caption,omitempty"`
+	Credit   string                             `json:"credit,omitempty"`
+	Srcset   map[string]string                  `json:"srcset,omitempty"`
+	Variants map[string]map[string]ImageVariant `json:"variants,omitempty"`
+}
+
+type ImageVariant struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// UploadedImage is what the upload endpoint and the status endpoint return.
+// Duplicate reports that the same picture had been uploaded before.
+type UploadedImage struct {
+	URL       string    `json:"url"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Size      int64     `json:"size"`
+	Duplicate bool      `json:"duplicate"`
+	StatusURL string    `json:"status_url,omitempty"`
+	Images    *ImageSet `json:"images"`
+}
+
+// ImageSet lists the variants made so far.
+func (m *Media) ImageSet() *ImageSet {
+	set := &ImageSet{
+		ID: m.ID, Status: m.Status, URL: m.URL, Width: m.Width, Height: m.Height,
+		Alt: m.Alt, Caption: m.Caption, Credit: m.Credit,
+	}
+	if len(m.Variants) == 0 {
+		return set
+	}
+
+	variants := append([]MediaVariant(nil), m.Variants...)
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].Width < variants[j].Width })
+
+	set.Srcset = map[string]string{}
+	set.Variants = map[string]map[string]ImageVariant{}
+	for _, v := range variants {
+		if set.Variants[v.Name] == nil {
+			set.Variants[v.Name] = map[string]ImageVariant{}
+		}
+		set.Variants[v.Name][v.Format] = ImageVariant{URL: v.URL, Width: v.Width, Height: v.Height}
+
+		if v.Name != MediaOpenGraph {
+			entry := v.URL + " " + strconv.Itoa(v.Width) + "w"
+			if set.Srcset[v.Format] != "" {
+				entry = set.Srcset[v.Format] + ", " + entry
+			}
+			set.Srcset[v.Format] = entry
+		}
+	}
+	return set
+}
+
+// Variant returns the URL of a named variant, preferring format, or "".
+func (s *ImageSet) Variant(name, format string) string {
+	if s == nil {
+		return ""
+	}
+	formats := s.Variants[name]
+	if v, ok := formats[format]; ok {
+		return v.URL
+	}
+	for f, v := range formats {
+		if f != "webp" {
+			return v.URL
+		}
+	}
+	return ""
+}
+
+// MediaUsage lists what points at a media file. Trashed articles count, as
+// they can still be restored.
+type MediaUsage struct {
+	MediaID         int             `json:"media_id"`
+	Articles        []MediaArticle  `json:"articles"`
+	LiveBlogEntries []MediaLiveBlog `json:"live_blog_entries"`
+	Users           []MediaUser     `json:"users"`
+}
+
+// MediaArticle is an article using a media file, as its main image
+// (InImage), in its body (InContent) or both.
+type MediaArticle struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	Status    string `json:"status"`
+	Trashed   bool   `json:"trashed"`
+	InImage   bool   `json:"in_image"`
+	InContent bool   `json:"in_content"`
+}
+
+type MediaLiveBlog struct {
+	ID         int `json:"id"`
+	LiveBlogID int `json:"live_blog_id"`
+	ArticleID  int `json:"article_id"`
+}
+
+// MediaUser is a user with the media file as their avatar.
+type MediaUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Count is how many places use the file.
+func (u *MediaUsage) Count() int {
+	return len(u.Articles) + len(u.LiveBlogEntries) + len(u.Users)
+}
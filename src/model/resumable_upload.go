@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Resumable upload states. An upload is processing while its finished file
+// is checked and stored, which happens once, after its last chunk.
+const (
+	UploadReceiving  = "uploading"
+	UploadProcessing = "processing"
+	UploadComplete   = "complete"
+	UploadFailed     = "failed"
+)
+
+// ResumableUpload is a file sent in chunks over the tus protocol, so that a
+// dropped connection only costs the chunk in flight. Each chunk is kept as
+// a part in the upload storage until the last one is in; the parts are then
+// joined into a media library file, which can be attached to an article.
+type ResumableUpload struct {
+	// Random, so that upload URLs cannot be guessed
+	ID     string `gorm:"type:varchar(32);primaryKey" json:"id"`
+	UserID int    `gorm:"not null;index" json:"user_id"`
+	Length int64  `gorm:"not null" json:"length"`
+	Offset int64  `gorm:"not null;default:0" json:"offset"`
+	// Chunks received, each stored as a part of its own
+	Parts int `gorm:"not null;default:0" json:"parts"`
+	// Upload-Metadata as the client sent it, handed back on HEAD
+	Metadata string `gorm:"type:text" json:"-"`
+	Filename string `gorm:"type:varchar(255)" json:"filename"`
+	// SHA-256 state over the bytes received, so the finished file is named
+	// without reading it all again
+	HashState []byte `json:"-"`
+	Status    string `gorm:"type:varchar(20);not null;index" json:"status"`
+	// Why the finished file was refused
+	Error   string `gorm:"type:text" json:"error,omitempty"`
+	MediaID *int   `json:"media_id"`
+	Media   *Media `gorm:"constraint:OnDelete:SET NULL" json:"media,omitempty"`
+	// Moved on with every chunk; an upload idle past it is abandoned
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
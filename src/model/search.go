@@ -0,0 +1,17 @@
+package model
+
+// SearchResult is an article matched by full-text search. The highlight
+// fields mark matched words with <mark>…</mark>; the rest is HTML-escaped.
+type SearchResult struct {
+	Article
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// RelatedArticle is a recommendation with its similarity to the source
+// article, between 0 and 1.
+type RelatedArticle struct {
+	Article
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// StreamEvent is one message for readers connected to a live stream, such as
+// the breaking news ticker. Every process reads new rows and passes them to
+// its own connections; the ID doubles as the SSE event id.
+type StreamEvent struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	Topic     string    `gorm:"type:varchar(60);not null;index" json:"topic"`
+	Type      string    `gorm:"type:varchar(30);not null" json:"type"`
+	Data      string    `gorm:"type:text;not null" json:"data"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type Tag struct {
+	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Slug      string    `gorm:"type:varchar(150);not null;uniqueIndex" json:"slug"`
+	Name      string    `gorm:"type:varchar(150);not null" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TagCount is a tag with the number of published articles carrying it.
+type TagCount struct {
+	Tag
+	ArticleCount int64 `json:"article_count"`
+}
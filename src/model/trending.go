@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// ArticleShareStat is the number of shares of an article to one network in
+// one hour.
+type ArticleShareStat struct {
+	ArticleID int       `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	Hour      time.Time `gorm:"primaryKey;index" json:"hour"`
+	Network   string    `gorm:"primaryKey;type:varchar(30)" json:"network"`
+	Shares    int64     `gorm:"not null;default:0" json:"shares"`
+}
+
+// ArticleSharer records that a visitor's share of an article has been counted
+// in one de-duplication window, as ArticleVisit does for views. Rows are
+// pruned once their window has passed.
+type ArticleSharer struct {
+	ArticleID int       `gorm:"primaryKey;autoIncrement:false"`
+	Visitor   string    `gorm:"primaryKey;type:char(32)"`
+	Bucket    int64     `gorm:"primaryKey"` // start of the window, in windows since the epoch
+	SharedAt  time.Time `gorm:"not null;index"`
+}
+
+// TrendingEntry is one place in a precomputed trending list. CategoryID 0 is
+// the site-wide list; a category's list includes its subcategories.
+type TrendingEntry struct {
+	CategoryID int       `gorm:"primaryKey;autoIncrement:false" json:"category_id"`
+	Rank       int       `gorm:"primaryKey;autoIncrement:false" json:"rank"`
+	ArticleID  int       `gorm:"not null;index" json:"article_id"`
+	Score      float64   `gorm:"not null" json:"score"`
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+// TrendingArticle is an article with its trending score.
+type TrendingArticle struct {
+	Article
+	Score float64 `json:"score"`
+}
@@ -11,6 +11,7 @@ type User struct {
 	Phone        string    `gorm:"type:varchar(20)" json:"phone"`
 	PasswordHash string    `gorm:"type:text;not null" json:"-"` // never expose in JSON
 	AvatarURL    string    `gorm:"type:text" json:"avatar_url"`
+	Role         string    `gorm:"type:varchar(20);not null;default:'user'" json:"role"` // see config.RoleRights
 	Status       string    `gorm:"type:varchar(20);not null;default:'active';check:status IN ('active','inactive','banned')" json:"status"`
 	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
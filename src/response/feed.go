@@ -0,0 +1,97 @@
+package response
+
+import "encoding/xml"
+
+// RSS 2.0 document, with Atom self links and content:encoded for the full
+// article body.
+type RSS struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	AtomNS       string     `xml:"xmlns:atom,attr"`
+	ContentNS    string     `xml:"xmlns:content,attr"`
+	DublinCoreNS string     `xml:"xmlns:dc,attr"`
+	Channel      RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      AtomLink  `xml:"atom:link"`
+	Items         []RSSItem `xml:"item"`
+}
+
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        RSSGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	Content     CDATA         `xml:"content:encoded"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Category    string        `xml:"category,omitempty"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *RSSEnclosure `xml:"enclosure,omitempty"`
+}
+
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSSEnclosure length is required by the spec, so an enclosure is only
+// given when its size is known.
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type CDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// Atom 1.0 document.
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"xml:lang,attr,omitempty"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Links     []AtomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Author    AtomPerson `xml:"author"`
+	Category  *AtomTerm  `xml:"category,omitempty"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	// Size in bytes of an enclosure
+	Length int64 `xml:"length,attr,omitempty"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomTerm struct {
+	Term string `xml:"term,attr"`
+}
+
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
@@ -30,6 +30,8 @@ type SuccessWithPaginate[T any] struct {
 	Limit        int    `json:"limit"`
 	TotalPages   int64  `json:"total_pages"`
 	TotalResults int64  `json:"total_results"`
+	// Keyset cursor for the next page, on endpoints that support one
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ErrorDetails struct {
@@ -0,0 +1,43 @@
+package response
+
+import "encoding/xml"
+
+type SitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []SitemapRef `xml:"sitemap"`
+}
+
+type SitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet is a sitemap, with the Google News and image extensions.
+type URLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	NewsNS  string       `xml:"xmlns:news,attr,omitempty"`
+	ImageNS string       `xml:"xmlns:image,attr,omitempty"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc     string        `xml:"loc"`
+	LastMod string        `xml:"lastmod,omitempty"`
+	News    *SitemapNews  `xml:"news:news,omitempty"`
+	Image   *SitemapImage `xml:"image:image,omitempty"`
+}
+
+type SitemapNews struct {
+	Publication     SitemapPublication `xml:"news:publication"`
+	PublicationDate string             `xml:"news:publication_date"`
+	Title           string             `xml:"news:title"`
+}
+
+type SitemapPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+type SitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
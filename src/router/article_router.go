@@ -2,7 +2,9 @@ package router
 
 import (
 	"app/src/controller"
+	"app/src/middleware"
 	"app/src/service"
+	"strconv"
 
 	"time"
 
@@ -10,8 +12,8 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cache"
 )
 
-func ArticleRoutes(router fiber.Router, s service.ArticleService) {
-	articleController := controller.NewArticleController(s)
+func ArticleRoutes(router fiber.Router, s service.ArticleService, u service.UserService, v service.ViewService, i service.ImageService) {
+	articleController := controller.NewArticleController(s, v, i)
 
 	// Cache Config - Include full URI (with query params) in cache key
 	cacheConfig := cache.Config{
@@ -19,18 +21,40 @@ func ArticleRoutes(router fiber.Router, s service.ArticleService) {
 		CacheControl: true,
 		KeyGenerator: func(c *fiber.Ctx) string {
 			// Include the full path AND query string in the cache key
-			// This ensures different query parameters get different cached responses
-			return c.OriginalURL()
+			// This ensures different query parameters get different cached responses.
+			// The article version changes on every edit, publish or expiry, so stale
+			// lists are dropped immediately instead of living out the 30s.
+			return c.OriginalURL() + "#" + strconv.FormatInt(service.ArticleVersion(), 10)
 		},
 	}
 
 	router.Get("/articles", cache.New(cacheConfig), articleController.GetAll)
-	router.Get("/article", articleController.GetByID) // Don't cache detailed view to ensure hit count/freshness if needed
+	router.Get("/article", articleController.GetByID)            // Not cached so every view is counted
+	router.Get("/article/related", articleController.GetRelated) // Before :slug, which would match it
+	router.Get("/article/:slug", articleController.GetBySlug)
 	router.Get("/featured", cache.New(cacheConfig), articleController.GetFeatured)
-	router.Get("/categories", cache.New(cacheConfig), articleController.GetCategories)
-	
-	router.Post("/add-article", articleController.CreateArticle)
-	router.Post("/upload-image", articleController.UploadImage)
-	router.Post("/delete-article", articleController.DeleteByID)
-	router.Post("/update-article", articleController.UpdateArticle)
+	router.Get("/most-read", cache.New(cacheConfig), articleController.GetMostRead)
+	router.Get("/search", cache.New(cacheConfig), articleController.Search)
+
+	router.Post("/add-article", middleware.Auth(u, "submitArticles"), articleController.CreateArticle)
+	router.Post("/upload-image", middleware.Auth(u, "submitArticles"), articleController.UploadImage)
+	router.Get("/images/:id", middleware.Auth(u, "submitArticles"), articleController.GetImage)
+	router.Post("/delete-article", middleware.Auth(u, "manageArticles"), articleController.DeleteByID)
+	router.Post("/update-article", middleware.Auth(u, "submitArticles"), articleController.UpdateArticle)
+	router.Post("/bulk-articles", middleware.Auth(u), articleController.Bulk) // Rights depend on the operation
+
+	// Trash: deleted articles can be restored until they are purged
+	router.Get("/article-trash", middleware.Auth(u, "manageTrash"), articleController.GetTrash)
+	router.Post("/restore-article", middleware.Auth(u, "manageTrash"), articleController.Restore)
+	router.Post("/purge-article", middleware.Auth(u, "manageTrash"), articleController.Purge)
+
+	// Editorial workflow
+	router.Get("/dashboard-articles", middleware.Auth(u, "getArticles"), articleController.GetDashboard)
+	router.Get("/article-history", middleware.Auth(u, "getArticles"), articleController.GetHistory)
+	router.Post("/article-transition", middleware.Auth(u), articleController.Transition)
+
+	// Revision history
+	router.Get("/article-revisions", middleware.Auth(u, "getArticles"), articleController.GetRevisions)
+	router.Get("/article-revision-diff", middleware.Auth(u, "getArticles"), articleController.DiffRevisions)
+	router.Post("/restore-article-revision", middleware.Auth(u, "submitArticles"), articleController.RestoreRevision)
 }
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func BreakingNewsRoutes(v1 fiber.Router, s service.BreakingNewsService, u service.UserService, e service.EventBroker) {
+	breakingController := controller.NewBreakingNewsController(s, e)
+
+	breakingGroup := v1.Group("/breaking-news")
+	breakingGroup.Get("/", breakingController.GetLive)
+	breakingGroup.Get("/stream", breakingController.Stream)
+	breakingGroup.Get("/all", middleware.Auth(u, "manageBreakingNews"), breakingController.GetAll)
+	breakingGroup.Post("/", middleware.Auth(u, "manageBreakingNews"), breakingController.Create)
+	breakingGroup.Post("/:breakingId/expire", middleware.Auth(u, "manageBreakingNews"), breakingController.Expire)
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CategoryRoutes(v1 fiber.Router, s service.CategoryService, u service.UserService) {
+	categoryController := controller.NewCategoryController(s)
+
+	categoryGroup := v1.Group("/categories")
+	categoryGroup.Get("/", categoryController.GetAll)
+	categoryGroup.Post("/", middleware.Auth(u, "manageCategories"), categoryController.Create)
+	categoryGroup.Put("/:categoryId", middleware.Auth(u, "manageCategories"), categoryController.Update)
+	categoryGroup.Delete("/:categoryId", middleware.Auth(u, "manageCategories"), categoryController.Delete)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FeedRoutes serves RSS and Atom at the site root, where feed readers look.
+func FeedRoutes(app *fiber.App, a service.ArticleService, c service.CategoryService, m service.MediaService) {
+	feedController := controller.NewFeedController(a, c, m)
+
+	app.Get("/feed.xml", feedController.RSS)
+	app.Get("/feed/atom.xml", feedController.Atom)
+	app.Get("/feed/:category/rss.xml", feedController.RSS)
+	app.Get("/feed/:category/atom.xml", feedController.Atom)
+}
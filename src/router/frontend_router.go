@@ -1,10 +1,25 @@
 package router
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"app/src/config"
+	"app/src/storage"
+	"app/src/utils"
+	"fmt"
+	"net/url"
 
-func FrontendRoutes(app *fiber.App) {
+	"github.com/gofiber/fiber/v2"
+)
+
+func FrontendRoutes(app *fiber.App, files storage.Storage) {
+	// Uploads are linked as /uploads/<name> wherever they are kept
+	if config.StorageDriver == storage.Local {
+		app.Static("/uploads", config.StorageLocalDir)
+	} else {
+		app.Get("/uploads/:name", uploadRedirect(files))
+		// Where the oldest articles link them
+		app.Get("/frontend/uploads/:name", uploadRedirect(files))
+	}
 	app.Static("/frontend", "./frontend")
-	app.Static("/uploads", "./frontend/uploads")
 
 	// Protected route helper
 	protected := func(c *fiber.Ctx) error {
@@ -26,3 +41,28 @@ func FrontendRoutes(app *fiber.App) {
 	app.Get("/admin", protected, func(c *fiber.Ctx) error { return c.SendFile("./frontend/admin.html") })
 	app.Get("/dashboard", protected, func(c *fiber.Ctx) error { return c.SendFile("./frontend/dashboard.html") })
 }
+
+// uploadRedirect sends readers on to the file in the object store, by a
+// link that expires for private buckets. Browsers may cache the redirect for
+// half the link's life.
+func uploadRedirect(files storage.Storage) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name, err := url.PathUnescape(c.Params("name"))
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+
+		target, maxAge := files.URL(name), 24*60*60
+		if config.StorageSignedURLs {
+			target, err = files.SignedURL(name, config.StorageURLExpiry)
+			if err != nil {
+				utils.Log.Errorf("Uploads: could not sign a link to %s: %+v", name, err)
+				return fiber.ErrNotFound
+			}
+			maxAge = int(config.StorageURLExpiry.Seconds() / 2)
+		}
+
+		c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", maxAge))
+		return c.Redirect(target, fiber.StatusFound)
+	}
+}
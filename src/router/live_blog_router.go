@@ -0,0 +1,25 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func LiveBlogRoutes(v1 fiber.Router, s service.LiveBlogService, u service.UserService, e service.EventBroker) {
+	liveBlogController := controller.NewLiveBlogController(s, e)
+
+	liveBlogGroup := v1.Group("/live-blogs")
+	liveBlogGroup.Get("/", liveBlogController.GetByArticle)
+	liveBlogGroup.Get("/:liveBlogId", liveBlogController.Get)
+	liveBlogGroup.Get("/:liveBlogId/entries", liveBlogController.GetEntries)
+	liveBlogGroup.Get("/:liveBlogId/stream", liveBlogController.Stream)
+
+	liveBlogGroup.Post("/", middleware.Auth(u, "manageLiveBlogs"), liveBlogController.Create)
+	liveBlogGroup.Put("/:liveBlogId", middleware.Auth(u, "manageLiveBlogs"), liveBlogController.Update)
+	liveBlogGroup.Post("/:liveBlogId/entries", middleware.Auth(u, "manageLiveBlogs"), liveBlogController.AddEntry)
+	liveBlogGroup.Put("/:liveBlogId/entries/:entryId", middleware.Auth(u, "manageLiveBlogs"), liveBlogController.UpdateEntry)
+	liveBlogGroup.Delete("/:liveBlogId/entries/:entryId", middleware.Auth(u, "manageLiveBlogs"), liveBlogController.DeleteEntry)
+}
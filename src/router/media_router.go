@@ -0,0 +1,20 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MediaRoutes(v1 fiber.Router, s service.MediaService, u service.UserService) {
+	mediaController := controller.NewMediaController(s)
+
+	mediaGroup := v1.Group("/media")
+	mediaGroup.Get("/", middleware.Auth(u, "submitArticles"), mediaController.GetAll)
+	mediaGroup.Get("/:mediaId", middleware.Auth(u, "submitArticles"), mediaController.Get)
+	mediaGroup.Get("/:mediaId/usage", middleware.Auth(u, "submitArticles"), mediaController.GetUsage)
+	mediaGroup.Put("/:mediaId", middleware.Auth(u, "submitArticles"), mediaController.Update)
+	mediaGroup.Delete("/:mediaId", middleware.Auth(u, "manageMedia"), mediaController.Delete)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PageRoutes serves the server-rendered article and category pages.
+func PageRoutes(app *fiber.App, a service.ArticleService, c service.CategoryService, v service.ViewService) {
+	pageController := controller.NewPageController(a, c, v)
+
+	app.Get("/news/:slug", pageController.Article)
+	app.Get("/category/:slug", pageController.Category)
+}
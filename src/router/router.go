@@ -3,18 +3,27 @@ package router
 import (
 	"app/src/config"
 	"app/src/service"
+	"app/src/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func Routes(app *fiber.App, db *gorm.DB) {
+func Routes(app *fiber.App, db *gorm.DB, ViewService service.ViewService, EventBroker service.EventBroker, ImageService service.ImageService, Files storage.Storage) {
 
 	UserService := service.NewUserService(db)
 	CommentService := service.NewCommentService(db)
 	ComService := service.NewComService(db)
 	AdminService := service.NewAdminService(db)
 	ArticleService := service.NewArticleService(db)
+	CategoryService := service.NewCategoryService(db)
+	TagService := service.NewTagService(db)
+	TrendingService := service.NewTrendingService(db)
+	SitemapService := service.NewSitemapService(db)
+	BreakingNewsService := service.NewBreakingNewsService(db)
+	LiveBlogService := service.NewLiveBlogService(db)
+	MediaService := service.NewMediaService(db, Files)
+	UploadService := service.NewUploadService(db, Files, ImageService)
 
 	v1 := app.Group("/v1")
 	api := app.Group("/api")
@@ -27,10 +36,27 @@ func Routes(app *fiber.App, db *gorm.DB) {
 	CommentRoutes(api, CommentService)
 	ComRoutes(v1, ComService)
 	ComRoutes(api, ComService)
-	ArticleRoutes(v1, ArticleService)  // Restful ones under v1
-	ArticleRoutes(api, ArticleService) // New ones under /api for the new frontend
+	ArticleRoutes(v1, ArticleService, UserService, ViewService, ImageService)  // Restful ones under v1
+	ArticleRoutes(api, ArticleService, UserService, ViewService, ImageService) // New ones under /api for the new frontend
 	AdminRoutes(v1, AdminService)
 	AdminRoutes(api, AdminService)
+	CategoryRoutes(v1, CategoryService, UserService)
+	CategoryRoutes(api, CategoryService, UserService)
+	TagRoutes(v1, TagService, UserService)
+	TagRoutes(api, TagService, UserService)
+	TrendingRoutes(v1, TrendingService)
+	TrendingRoutes(api, TrendingService)
+	BreakingNewsRoutes(v1, BreakingNewsService, UserService, EventBroker)
+	BreakingNewsRoutes(api, BreakingNewsService, UserService, EventBroker)
+	LiveBlogRoutes(v1, LiveBlogService, UserService, EventBroker)
+	LiveBlogRoutes(api, LiveBlogService, UserService, EventBroker)
+	MediaRoutes(v1, MediaService, UserService)
+	MediaRoutes(api, MediaService, UserService)
+	UploadRoutes(v1, UploadService, UserService)
+	UploadRoutes(api, UploadService, UserService)
+	FeedRoutes(app, ArticleService, CategoryService, MediaService)
+	SitemapRoutes(app, SitemapService)
+	PageRoutes(app, ArticleService, CategoryService, ViewService)
 
 	// TODO: add another routes here...
 
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SitemapRoutes serves the sitemaps and robots.txt at the site root.
+func SitemapRoutes(app *fiber.App, s service.SitemapService) {
+	sitemapController := controller.NewSitemapController(s)
+
+	app.Get("/robots.txt", sitemapController.Robots)
+	app.Get("/sitemap.xml", sitemapController.Index)
+	app.Get("/sitemaps/news.xml", sitemapController.News)
+	app.Get("/sitemaps/categories.xml", sitemapController.Categories)
+	app.Get("/sitemaps/articles-:page.xml", sitemapController.Articles)
+}
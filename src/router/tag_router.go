@@ -0,0 +1,20 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TagRoutes(v1 fiber.Router, s service.TagService, u service.UserService) {
+	tagController := controller.NewTagController(s)
+
+	tagGroup := v1.Group("/tags")
+	tagGroup.Get("/", tagController.GetAll)
+	tagGroup.Get("/suggest", tagController.Suggest)
+	tagGroup.Get("/:slug/articles", tagController.GetArticles)
+	tagGroup.Put("/:tagId", middleware.Auth(u, "manageTags"), tagController.Rename)
+	tagGroup.Post("/:tagId/merge", middleware.Auth(u, "manageTags"), tagController.Merge)
+}
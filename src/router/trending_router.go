@@ -0,0 +1,29 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cache"
+)
+
+func TrendingRoutes(v1 fiber.Router, s service.TrendingService) {
+	trendingController := controller.NewTrendingController(s)
+
+	// Lists are recomputed about once a minute; the version keeps them in
+	// step with unpublished articles
+	cacheConfig := cache.Config{
+		Expiration:   30 * time.Second,
+		CacheControl: true,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.OriginalURL() + "#" + strconv.FormatInt(service.ArticleVersion(), 10)
+		},
+	}
+
+	v1.Get("/trending", cache.New(cacheConfig), trendingController.GetTrending)
+	v1.Post("/share-article", middleware.ShareLimiter(), trendingController.Share)
+}
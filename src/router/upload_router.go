@@ -0,0 +1,25 @@
+package router
+
+import (
+	"app/src/controller"
+	"app/src/middleware"
+	"app/src/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func UploadRoutes(v1 fiber.Router, s service.UploadService, u service.UserService) {
+	uploadController := controller.NewUploadController(s)
+
+	// The tus protocol; Head comes before Get, which would also answer HEAD
+	uploadGroup := v1.Group("/uploads")
+	uploadGroup.Options("/", uploadController.Resumable, uploadController.Options)
+	uploadGroup.Post("/", uploadController.Resumable, middleware.Auth(u, "submitArticles"), uploadController.Create)
+	uploadGroup.Head("/:uploadId", uploadController.Resumable, middleware.Auth(u, "submitArticles"), uploadController.Head)
+	uploadGroup.Patch("/:uploadId", uploadController.Resumable, middleware.Auth(u, "submitArticles"), uploadController.Patch)
+	uploadGroup.Delete("/:uploadId", uploadController.Resumable, middleware.Auth(u, "submitArticles"), uploadController.Terminate)
+
+	// Plain JSON, for after the upload is complete
+	uploadGroup.Get("/:uploadId", middleware.Auth(u, "submitArticles"), uploadController.Get)
+	uploadGroup.Post("/:uploadId/attach", middleware.Auth(u, "submitArticles"), uploadController.Attach)
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"app/src/controller"
+	"app/src/middleware"
 	"app/src/service"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,4 +19,5 @@ func UserRoutes(v1 fiber.Router, u service.UserService) {
 	userGroup.Get("/:userId", userController.GetByUserId)
 	userGroup.Put("/:userId", userController.UpdateUser)
 	userGroup.Delete("/:userId", userController.DeleteUser)
+	userGroup.Put("/:userId/role", middleware.Auth(u, "manageUsers"), userController.SetRole)
 }
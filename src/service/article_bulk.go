@@ -0,0 +1,219 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/validation"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errBulkRollback undoes a bulk operation's transaction after a dry run or a
+// failed item.
+var errBulkRollback = errors.New("bulk operation rolled back")
+
+// bulkStep applies the operation to one locked article and returns the
+// fields it changed, nil for none.
+type bulkStep func(tx *gorm.DB, a *model.Article) (map[string][2]interface{}, error)
+
+// Bulk applies one operation to every article in req.IDs within a single
+// transaction. Every article gets a result; if any fails, or for a dry run,
+// the transaction is rolled back and nothing changes, but the results still
+// show what would have happened.
+func (s *articleService) Bulk(req *validation.BulkArticles, actor *model.User) (*model.BulkResult, error) {
+	step, err := s.bulkStep(req, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the caller's order, once per article
+	ids := make([]int, 0, len(req.IDs))
+	seen := make(map[int]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	result := &model.BulkResult{
+		Operation: req.Operation,
+		DryRun:    req.DryRun,
+		Items:     make([]model.BulkItemResult, 0, len(ids)),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock in id order so concurrent bulk edits cannot deadlock
+		var articles []model.Article
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Find(&articles).Error
+		if err != nil {
+			return err
+		}
+		byID := make(map[int]*model.Article, len(articles))
+		for i := range articles {
+			byID[articles[i].ID] = &articles[i]
+		}
+
+		for _, id := range ids {
+			item := model.BulkItemResult{ID: id}
+
+			a, ok := byID[id]
+			if !ok {
+				item.Result = model.BulkFailed
+				item.Error = "article not found"
+				result.Failed++
+				result.Items = append(result.Items, item)
+				continue
+			}
+
+			// A savepoint per article, so one failure does not abort the
+			// transaction and the rest still get a result
+			if err := tx.SavePoint("bulk_item").Error; err != nil {
+				return err
+			}
+			changes, err := step(tx, a)
+			switch {
+			case err != nil:
+				if rollbackErr := tx.RollbackTo("bulk_item").Error; rollbackErr != nil {
+					return rollbackErr
+				}
+				item.Result = model.BulkFailed
+				item.Error = bulkError(err)
+				result.Failed++
+			case len(changes) == 0:
+				item.Result = model.BulkUnchanged
+				result.Unchanged++
+			default:
+				item.Result = model.BulkChanged
+				item.Changes = changes
+				result.Changed++
+			}
+			result.Items = append(result.Items, item)
+		}
+
+		if req.DryRun || result.Failed > 0 {
+			return errBulkRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkRollback) {
+		return nil, err
+	}
+
+	result.Applied = err == nil
+	if result.Applied && result.Changed > 0 {
+		touchArticles()
+	}
+	return result, nil
+}
+
+// bulkStep checks the operation's parameters and the actor's rights once,
+// then returns the per-article step. Transitions need the right of their
+// action, so reporters can submit stories; everything else needs
+// manageArticles.
+func (s *articleService) bulkStep(req *validation.BulkArticles, actor *model.User) (bulkStep, error) {
+	if req.Operation != "transition" && (actor == nil || !config.HasRights(actor.Role, "manageArticles")) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "You don't have permission to "+req.Operation+" articles")
+	}
+
+	switch req.Operation {
+	case "feature", "unfeature":
+		featured := req.Operation == "feature"
+		return func(tx *gorm.DB, a *model.Article) (map[string][2]interface{}, error) {
+			if a.Featured == featured {
+				return nil, nil
+			}
+			changes := map[string][2]interface{}{"featured": {a.Featured, featured}}
+			return changes, bulkEdit(tx, a, map[string]interface{}{"featured": featured}, actor)
+		}, nil
+
+	case "move":
+		if req.Category == "" {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "category is required to move articles")
+		}
+		cat, err := findCategory(s.db, req.Category)
+		if err != nil {
+			return nil, err
+		}
+		return func(tx *gorm.DB, a *model.Article) (map[string][2]interface{}, error) {
+			if a.CategoryID != nil && *a.CategoryID == cat.ID {
+				return nil, nil
+			}
+			changes := map[string][2]interface{}{"category": {a.Category, cat.NameBn}}
+			return changes, bulkEdit(tx, a, map[string]interface{}{"category": cat.NameBn, "category_id": cat.ID}, actor)
+		}, nil
+
+	case "assign_author":
+		author := strings.TrimSpace(req.Author)
+		if author == "" {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "author is required to assign an author")
+		}
+		return func(tx *gorm.DB, a *model.Article) (map[string][2]interface{}, error) {
+			if a.Author == author {
+				return nil, nil
+			}
+			changes := map[string][2]interface{}{"author": {a.Author, author}}
+			return changes, bulkEdit(tx, a, map[string]interface{}{"author": author}, actor)
+		}, nil
+
+	case "transition":
+		action, ok := articleActions[req.Action]
+		if !ok {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "a valid action is required to transition articles")
+		}
+		if actor == nil || !config.HasRights(actor.Role, action.right) {
+			return nil, fiber.NewError(fiber.StatusForbidden, "You don't have permission to "+req.Action+" articles")
+		}
+		if action.needsNote && req.Note == "" {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "a note is required to "+req.Action+" an article")
+		}
+		transition := &validation.ArticleTransition{Action: req.Action, Note: req.Note}
+		return func(tx *gorm.DB, a *model.Article) (map[string][2]interface{}, error) {
+			from := a.Status
+			if err := applyTransition(tx, a, action, transition, actor); err != nil {
+				return nil, err
+			}
+			return map[string][2]interface{}{"status": {from, a.Status}}, nil
+		}, nil
+
+	case "delete":
+		return func(tx *gorm.DB, a *model.Article) (map[string][2]interface{}, error) {
+			if err := trashArticle(tx, a, actor); err != nil {
+				return nil, err
+			}
+			return map[string][2]interface{}{"deleted": {false, true}}, nil
+		}, nil
+	}
+
+	return nil, fiber.NewError(fiber.StatusBadRequest, "unknown operation")
+}
+
+// bulkEdit updates fields of a, bumping its version like UpdateArticle, and
+// records a revision.
+func bulkEdit(tx *gorm.DB, a *model.Article, updates map[string]interface{}, actor *model.User) error {
+	updates["version"] = a.Version + 1
+	updates["updated_at"] = time.Now()
+	if err := tx.Model(&model.Article{}).Where("id = ?", a.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	if err := tx.First(a, a.ID).Error; err != nil {
+		return err
+	}
+	return saveRevision(tx, a, actor, nil)
+}
+
+func bulkError(err error) string {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"app/src/utils"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// articleVersion changes whenever article data visible to readers may have
+// changed. Public response caches include it in their keys, so bumping it
+// invalidates every cached list at once.
+var articleVersion atomic.Int64
+
+// ArticleVersion returns the current article cache version.
+func ArticleVersion() int64 {
+	return articleVersion.Load()
+}
+
+func touchArticles() {
+	articleVersion.Add(1)
+}
+
+// WatchArticleChanges polls a cheap fingerprint of the articles table and
+// bumps the cache version when it moves. This catches changes made by other
+// processes (Prefork children, the scheduler, other containers) and embargo
+// windows that open or close without any write.
+func WatchArticleChanges(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var row struct {
+			LastUpdate *time.Time
+			Total      int64
+			Trashed    int64
+			Visible    int64
+		}
+		err := db.WithContext(ctx).Raw(`
+			SELECT MAX(updated_at) AS last_update,
+			       COUNT(*) AS total,
+			       COUNT(*) FILTER (WHERE deleted_at IS NOT NULL) AS trashed,
+			       COUNT(*) FILTER (WHERE status = 'published'
+			           AND (publish_at IS NULL OR publish_at <= now())
+			           AND (expire_at IS NULL OR expire_at > now())
+			           AND deleted_at IS NULL) AS visible
+			FROM articles`).Scan(&row).Error
+		if err != nil {
+			utils.Log.Warnf("Article watcher: fingerprint query failed: %+v", err)
+			continue
+		}
+
+		fingerprint := fmt.Sprintf("%v|%d|%d|%d", row.LastUpdate, row.Total, row.Trashed, row.Visible)
+		if last != "" && fingerprint != last {
+			touchArticles()
+		}
+		last = fingerprint
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/validation"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// articleCursor is a position in a listing ordered by updated_at desc, id
+// desc. Paging by position rather than offset keeps infinite scrolling
+// stable while new stories are published at the top.
+type articleCursor struct {
+	UpdatedAt time.Time
+	ID        int
+}
+
+func encodeArticleCursor(a *model.Article) string {
+	raw := fmt.Sprintf("%d:%d", a.UpdatedAt.UnixMicro(), a.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeArticleCursor(s string) (*articleCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	var micros int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micros, &id); err != nil || id <= 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+	}
+	return &articleCursor{UpdatedAt: time.UnixMicro(micros), ID: id}, nil
+}
+
+// listArticles pages through query, newest first. It returns the page, the
+// total number of matches and the cursor for the next page ("" on the last).
+// With params.Cursor set the page starts after that position and params.Page
+// is ignored.
+func listArticles(query *gorm.DB, params *validation.QueryArticle) ([]model.Article, int64, string, error) {
+	offset := paginate(&params.Page, &params.Limit, 20, 100)
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Model(&model.Article{}).Count(&total).Error; err != nil {
+		return nil, 0, "", err
+	}
+
+	page := query.Preload("Tags").Order("articles.updated_at desc, articles.id desc").Limit(params.Limit)
+	if params.Cursor != "" {
+		cursor, err := decodeArticleCursor(params.Cursor)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		page = page.Where("(articles.updated_at, articles.id) < (?, ?)", cursor.UpdatedAt, cursor.ID)
+	} else {
+		page = page.Offset(offset)
+	}
+
+	var items []model.Article
+	if err := page.Find(&items).Error; err != nil {
+		return nil, 0, "", err
+	}
+	attachImages(query.Session(&gorm.Session{NewDB: true}), articleRefs(items)...)
+
+	next := ""
+	if len(items) == params.Limit {
+		next = encodeArticleCursor(&items[len(items)-1])
+	}
+	return items, total, next, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"app/src/model"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticleCursorRoundTrip(t *testing.T) {
+	// Postgres keeps microseconds, and so does the cursor
+	updated := time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC)
+	a := &model.Article{ID: 42, UpdatedAt: updated.Add(238 * time.Nanosecond)}
+
+	cursor, err := decodeArticleCursor(encodeArticleCursor(a))
+	require.NoError(t, err)
+	assert.Equal(t, 42, cursor.ID)
+	assert.True(t, cursor.UpdatedAt.Equal(updated), "got %v", cursor.UpdatedAt)
+}
+
+func TestArticleCursorIsURLSafe(t *testing.T) {
+	a := &model.Article{ID: 1 << 30, UpdatedAt: time.Now()}
+	assert.NotContains(t, encodeArticleCursor(a), "=")
+	assert.NotContains(t, encodeArticleCursor(a), "+")
+	assert.NotContains(t, encodeArticleCursor(a), "/")
+}
+
+func TestDecodeArticleCursorRejects(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name, cursor string
+	}{
+		{"not base64", "%%%"},
+		{"no separator", encode("1700000000000000")},
+		{"not numbers", encode("yesterday:7")},
+		{"zero id", encode("1700000000000000:0")},
+		{"negative id", encode("1700000000000000:-3")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeArticleCursor(tt.cursor)
+			var fiberErr *fiber.Error
+			if assert.True(t, errors.As(err, &fiberErr), "got %v", err) {
+				assert.Equal(t, fiber.StatusBadRequest, fiberErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// attachImages fills in the sizes of each article's image, for those
+// uploaded here. It only adds to the response, so a failed lookup is logged
+// and the articles are returned without them.
+func attachImages(db *gorm.DB, articles ...*model.Article) {
+	urls := make([]string, 0, len(articles))
+	for _, a := range articles {
+		if a.Image != nil && strings.HasPrefix(*a.Image, "/uploads/") {
+			urls = append(urls, *a.Image)
+		}
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	var media []model.Media
+	if err := db.Preload("Variants").Where("url IN ?", urls).Find(&media).Error; err != nil {
+		utils.Log.Errorf("Failed to load article images: %+v", err)
+		return
+	}
+	sets := make(map[string]*model.ImageSet, len(media))
+	for i := range media {
+		sets[media[i].URL] = media[i].ImageSet()
+	}
+	for _, a := range articles {
+		if a.Image != nil {
+			a.Images = sets[*a.Image]
+		}
+	}
+}
+
+func articleRefs(items []model.Article) []*model.Article {
+	refs := make([]*model.Article, len(items))
+	for i := range items {
+		refs[i] = &items[i]
+	}
+	return refs
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// Related articles are picked from the relatedPoolSize most recent published
+// stories. The score mixes text similarity, shared tags, the same category
+// and how recent the candidate is, with these weights.
+const (
+	relatedPoolSize       = 1000
+	relatedTextWeight     = 0.6
+	relatedTagWeight      = 0.25
+	relatedCategoryWeight = 0.1
+	relatedRecencyWeight  = 0.05
+	relatedRecencyDays    = 30.0
+	relatedTitleBoost     = 3
+	relatedCacheSize      = 5000
+	relatedKeep           = 40
+	relatedTTL            = time.Hour
+)
+
+// relatedEntry is a cached recommendation, valid while the source article's
+// version and updated_at are unchanged. It is also refreshed after
+// relatedTTL so that newer stories get a chance.
+type relatedEntry struct {
+	version    int
+	updatedAt  time.Time
+	computedAt time.Time
+	ids        []int
+	scores     map[int]float64
+}
+
+// relatedPool is the tokenised candidate pool, rebuilt when any article
+// changes (see ArticleVersion).
+type relatedPool struct {
+	version  int64
+	articles []model.Article
+	terms    []map[string]float64
+	df       map[string]int
+}
+
+type relatedCache struct {
+	mu      sync.Mutex
+	entries map[int]relatedEntry
+	pool    *relatedPool
+	rebuild singleflight.Group
+}
+
+func (c *relatedCache) get(a *model.Article) (relatedEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[a.ID]
+	if !ok || entry.version != a.Version || !entry.updatedAt.Equal(a.UpdatedAt) || time.Since(entry.computedAt) > relatedTTL {
+		return relatedEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *relatedCache) put(a *model.Article, entry relatedEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Crude but bounded: start over rather than track usage
+	if c.entries == nil || len(c.entries) >= relatedCacheSize {
+		c.entries = make(map[int]relatedEntry)
+	}
+	entry.version = a.Version
+	entry.updatedAt = a.UpdatedAt
+	entry.computedAt = time.Now()
+	c.entries[a.ID] = entry
+}
+
+// GetRelated recommends up to limit published articles similar to article id.
+// Recommendations are cached until the source article changes; candidates
+// unpublished since then are dropped when the cached list is read.
+func (s *articleService) GetRelated(id, limit int) ([]model.RelatedArticle, error) {
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+
+	source, err := s.GetPublishedByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "article not found")
+		}
+		return nil, err
+	}
+
+	entry, ok := s.related.get(source)
+	if !ok {
+		entry, err = s.computeRelated(source)
+		if err != nil {
+			return nil, err
+		}
+		s.related.put(source, entry)
+	}
+
+	ids := entry.ids
+	if len(ids) > limit*2 {
+		// Leave room for a few candidates that are no longer published
+		ids = ids[:limit*2]
+	}
+	if len(ids) == 0 {
+		return []model.RelatedArticle{}, nil
+	}
+
+	var articles []model.Article
+	if err := s.db.Scopes(published).Preload("Tags").Where("articles.id IN ?", ids).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]model.RelatedArticle, 0, limit)
+	for _, a := range articles {
+		items = append(items, model.RelatedArticle{Article: a, Score: entry.scores[a.ID]})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Score > items[j].Score })
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	refs := make([]*model.Article, len(items))
+	for i := range items {
+		refs[i] = &items[i].Article
+	}
+	attachImages(s.db, refs...)
+	return items, nil
+}
+
+// relatedPool returns the candidate pool. Once there is one, a stale pool
+// keeps being served while a single rebuild runs in the background; only the
+// first call waits for the pool to be loaded.
+func (s *articleService) relatedPool() (*relatedPool, error) {
+	version := ArticleVersion()
+	s.related.mu.Lock()
+	pool := s.related.pool
+	s.related.mu.Unlock()
+	if pool != nil && pool.version == version {
+		return pool, nil
+	}
+
+	rebuild := s.related.rebuild.DoChan("pool", func() (interface{}, error) {
+		return s.buildRelatedPool()
+	})
+	if pool != nil {
+		return pool, nil
+	}
+	result := <-rebuild
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Val.(*relatedPool), nil
+}
+
+// buildRelatedPool loads and tokenises the candidate pool and makes it the
+// current one.
+func (s *articleService) buildRelatedPool() (*relatedPool, error) {
+	pool := &relatedPool{version: ArticleVersion(), df: map[string]int{}}
+	err := s.db.Scopes(published).
+		Preload("Tags").
+		Select("articles.id", "articles.title", "articles.content", "articles.category_id", "articles.created_at", "articles.publish_at").
+		Order("articles.created_at desc").
+		Limit(relatedPoolSize).
+		Find(&pool.articles).Error
+	if err != nil {
+		utils.Log.Errorf("Failed to load the related articles pool: %+v", err)
+		return nil, err
+	}
+
+	pool.terms = make([]map[string]float64, len(pool.articles))
+	for i := range pool.articles {
+		pool.terms[i] = termFrequencies(&pool.articles[i])
+		for term := range pool.terms[i] {
+			pool.df[term]++
+		}
+	}
+
+	s.related.mu.Lock()
+	s.related.pool = pool
+	s.related.mu.Unlock()
+	return pool, nil
+}
+
+// computeRelated ranks the candidate pool against source. Term weights are
+// TF-IDF with document frequencies taken from the pool; title words count
+// relatedTitleBoost times.
+func (s *articleService) computeRelated(source *model.Article) (relatedEntry, error) {
+	pool, err := s.relatedPool()
+	if err != nil {
+		return relatedEntry{}, err
+	}
+
+	n := float64(len(pool.articles) + 1)
+	weigh := func(tf map[string]float64) map[string]float64 {
+		vec := make(map[string]float64, len(tf))
+		for term, count := range tf {
+			// Smoothed, as the source's own terms may not be in the pool
+			vec[term] = (1 + math.Log(count)) * math.Log(n/float64(pool.df[term]+1))
+		}
+		return vec
+	}
+
+	sourceTerms := termFrequencies(source)
+
+	sourceVec := weigh(sourceTerms)
+	sourceTags := map[int]bool{}
+	for _, tag := range source.Tags {
+		sourceTags[tag.ID] = true
+	}
+
+	entry := relatedEntry{scores: map[int]float64{}}
+	now := time.Now()
+	for i := range pool.articles {
+		candidate := &pool.articles[i]
+		if candidate.ID == source.ID {
+			continue
+		}
+
+		text := cosine(sourceVec, weigh(pool.terms[i]))
+
+		tags := 0.0
+		if len(sourceTags) > 0 {
+			shared := 0
+			for _, tag := range candidate.Tags {
+				if sourceTags[tag.ID] {
+					shared++
+				}
+			}
+			tags = float64(shared) / float64(len(sourceTags))
+		}
+
+		category := 0.0
+		if source.CategoryID != nil && candidate.CategoryID != nil && *source.CategoryID == *candidate.CategoryID {
+			category = 1
+		}
+
+		if text == 0 && tags == 0 {
+			// Same category and recent alone do not make a story related
+			continue
+		}
+
+		publishedAt := candidate.Created
+		if candidate.PublishAt != nil {
+			publishedAt = *candidate.PublishAt
+		}
+		recency := math.Exp(-now.Sub(publishedAt).Hours() / 24 / relatedRecencyDays)
+
+		entry.scores[candidate.ID] = relatedTextWeight*text +
+			relatedTagWeight*tags +
+			relatedCategoryWeight*category +
+			relatedRecencyWeight*recency
+		entry.ids = append(entry.ids, candidate.ID)
+	}
+
+	sort.SliceStable(entry.ids, func(i, j int) bool {
+		return entry.scores[entry.ids[i]] > entry.scores[entry.ids[j]]
+	})
+	if len(entry.ids) > relatedKeep {
+		entry.ids = entry.ids[:relatedKeep]
+	}
+	return entry, nil
+}
+
+func termFrequencies(a *model.Article) map[string]float64 {
+	tf := map[string]float64{}
+	for _, term := range utils.Terms(a.Title) {
+		tf[term] += relatedTitleBoost
+	}
+	for _, term := range utils.Terms(utils.StripTags(a.Content)) {
+		tf[term]++
+	}
+	return tf
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, weight := range a {
+		normA += weight * weight
+		dot += weight * b[term]
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevisionDiff compares two revisions of the same article.
+type RevisionDiff struct {
+	ArticleID int                       `json:"article_id"`
+	From      int                       `json:"from"`
+	To        int                       `json:"to"`
+	Title     []utils.DiffOp            `json:"title"`
+	Content   []utils.DiffOp            `json:"content"`
+	Changes   map[string][2]interface{} `json:"changes,omitempty"`
+}
+
+// saveRevision snapshots a inside tx as the article's next revision.
+func saveRevision(tx *gorm.DB, a *model.Article, actor *model.User, restoredFrom *int) error {
+	var next int
+	err := tx.Model(&model.ArticleRevision{}).
+		Where("article_id = ?", a.ID).
+		Select("COALESCE(MAX(revision), 0) + 1").
+		Scan(&next).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.ArticleRevision{
+		ArticleID:    a.ID,
+		Revision:     next,
+		Title:        a.Title,
+		Content:      a.Content,
+		Category:     a.Category,
+		Image:        a.Image,
+		Featured:     a.Featured,
+		EditorID:     actorID(actor),
+		EditorName:   actorName(actor),
+		RestoredFrom: restoredFrom,
+	}).Error
+}
+
+func (s *articleService) GetRevisions(id int) ([]model.ArticleRevision, error) {
+	var items []model.ArticleRevision
+	err := s.db.
+		Omit("content").
+		Where("article_id = ?", id).
+		Order("revision desc").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *articleService) getRevision(id, revision int) (*model.ArticleRevision, error) {
+	var rev model.ArticleRevision
+	if err := s.db.Where("article_id = ? AND revision = ?", id, revision).First(&rev).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "revision not found")
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (s *articleService) DiffRevisions(id, from, to int) (*RevisionDiff, error) {
+	a, err := s.getRevision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.getRevision(id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RevisionDiff{
+		ArticleID: id,
+		From:      from,
+		To:        to,
+		Title:     utils.DiffWords(a.Title, b.Title),
+		Content:   utils.DiffWords(a.Content, b.Content),
+		Changes:   map[string][2]interface{}{},
+	}
+
+	if a.Category != b.Category {
+		diff.Changes["category"] = [2]interface{}{a.Category, b.Category}
+	}
+	if a.Featured != b.Featured {
+		diff.Changes["featured"] = [2]interface{}{a.Featured, b.Featured}
+	}
+	if (a.Image == nil) != (b.Image == nil) || (a.Image != nil && *a.Image != *b.Image) {
+		diff.Changes["image"] = [2]interface{}{a.Image, b.Image}
+	}
+
+	return diff, nil
+}
+
+// RestoreRevision copies an old revision back onto the article. The restore
+// itself becomes a new revision, so history is never rewritten. As with
+// UpdateArticle, an article changed since req.Version is not overwritten.
+func (s *articleService) RestoreRevision(req *validation.RestoreRevision, actor *model.User) (*model.Article, error) {
+	rev, err := s.getRevision(req.ID, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var article model.Article
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&article, req.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "article not found")
+			}
+			return err
+		}
+		if err := checkEditable(&article, actor); err != nil {
+			return err
+		}
+		// A zero version comes from If-Match: * and restores over any version
+		if req.Version != 0 && req.Version != article.Version {
+			return &StaleArticleError{Current: &article}
+		}
+
+		article.Title = rev.Title
+		article.Content = rev.Content
+		article.Category = rev.Category
+		article.Image = rev.Image
+		article.Featured = rev.Featured
+		article.Version++
+
+		// Revisions name their category; look it up again, as UpdateArticle does
+		article.CategoryID = nil
+		if err := assignCategory(tx, &article); err != nil {
+			return err
+		}
+
+		err := tx.Model(&article).
+			Select("title", "content", "category", "category_id", "image", "featured", "version", "updated_at").
+			Updates(&article).Error
+		if err != nil {
+			return err
+		}
+
+		if err := assignSlug(tx, &article); err != nil {
+			return err
+		}
+		return saveRevision(tx, &article, actor, &rev.Revision)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	touchArticles()
+	return &article, nil
+}
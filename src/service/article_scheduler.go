@@ -0,0 +1,103 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArticleScheduler publishes scheduled articles when their publish_at is
+// reached, archives published ones once expire_at has passed and purges
+// articles left in the trash past the retention period.
+//
+// All state lives in the database, so nothing is lost across restarts: a
+// missed moment is caught up on the next tick. Each flip is a single
+// conditional UPDATE, so even if several schedulers run at once (Prefork,
+// multiple containers) every article is flipped and logged exactly once.
+type ArticleScheduler struct {
+	db *gorm.DB
+}
+
+func NewArticleScheduler(db *gorm.DB) *ArticleScheduler {
+	return &ArticleScheduler{db: db}
+}
+
+// Run ticks until ctx is cancelled.
+func (s *ArticleScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick performs one scheduling pass.
+func (s *ArticleScheduler) Tick(ctx context.Context) {
+	published, err := s.flip(ctx, "publish",
+		model.ArticleStatusScheduled, model.ArticleStatusPublished, "publish_at <= now()")
+	if err != nil {
+		utils.Log.Errorf("Scheduler: publishing failed: %+v", err)
+	}
+
+	expired, err := s.flip(ctx, "expire",
+		model.ArticleStatusPublished, model.ArticleStatusArchived, "expire_at <= now()")
+	if err != nil {
+		utils.Log.Errorf("Scheduler: expiring failed: %+v", err)
+	}
+
+	if published+expired > 0 {
+		utils.Log.Infof("Scheduler: published %d, expired %d article(s)", published, expired)
+		touchArticles()
+	}
+
+	purged, err := purgeTrash(ctx, s.db)
+	if err != nil {
+		utils.Log.Errorf("Scheduler: purging the trash failed: %+v", err)
+	}
+	if purged > 0 {
+		utils.Log.Infof("Scheduler: purged %d article(s) from the trash", purged)
+	}
+}
+
+func (s *ArticleScheduler) flip(ctx context.Context, action, from, to, due string) (int, error) {
+	var flipped []model.Article
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&flipped).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("status = ?", from).
+			Where(due).
+			Updates(map[string]interface{}{"status": to, "updated_at": time.Now()}).Error
+		if err != nil || len(flipped) == 0 {
+			return err
+		}
+
+		transitions := make([]model.ArticleTransition, 0, len(flipped))
+		for _, a := range flipped {
+			transitions = append(transitions, model.ArticleTransition{
+				ArticleID:  a.ID,
+				Action:     action,
+				FromStatus: from,
+				ToStatus:   to,
+				ActorName:  "scheduler",
+			})
+		}
+		return tx.Create(&transitions).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(flipped), nil
+}
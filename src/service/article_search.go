@@ -0,0 +1,136 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Search ranking: text relevance, boosted by up to searchRecencyBoost for
+// brand-new stories and decaying with searchRecencyDays.
+const (
+	searchRecencyBoost = 0.5
+	searchRecencyDays  = 7.0
+)
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=10, MaxWords=30, FragmentDelimiter=\" … \""
+
+// escapeHTMLSQL escapes a text column for ts_headline output that is
+// rendered as HTML, so only the <mark> tags it adds are markup.
+const escapeHTMLSQL = "replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+
+// stripTagsSQL reduces an HTML column to its text, as the search column is
+// built in migrateSearch, so snippets show words and not markup.
+const stripTagsSQL = "replace(regexp_replace(%s, '<[^>]+>', ' ', 'g'), '&nbsp;', ' ')"
+
+// banglishCandidates is how many Bengali spellings each romanised word of a
+// query is expanded to.
+const banglishCandidates = 5
+
+// tsQuery turns the reader's query into a tsquery expression. The query uses
+// web search syntax: "quoted phrases", -excluded words and OR. Queries typed
+// in Latin letters are also matched against their likely Bengali spellings.
+func tsQuery(q string) (string, []interface{}) {
+	if utils.IsRomanized(q) && !strings.Contains(q, `"`) {
+		if expanded := banglishQuery(q); expanded != "" {
+			return "to_tsquery('simple', ?)", []interface{}{expanded}
+		}
+	}
+	return "websearch_to_tsquery('simple', ?)", []interface{}{utils.SearchText(q)}
+}
+
+// banglishQuery builds to_tsquery input for a romanised query: each word
+// matches itself or, as a prefix so inflected forms (বাজেটে, বাজেটের) count,
+// any of its Bengali candidates. Words are ANDed unless joined by "or", and a
+// leading "-" excludes a word with all its spellings.
+func banglishQuery(q string) string {
+	var b strings.Builder
+	join := ""
+	for _, field := range strings.Fields(strings.ToLower(q)) {
+		if field == "or" {
+			if join != "" {
+				join = " | "
+			}
+			continue
+		}
+		negate := strings.HasPrefix(field, "-")
+		word := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, field)
+		if word == "" {
+			continue
+		}
+
+		alternatives := []string{"'" + word + "'"}
+		for _, c := range utils.BanglishCandidates(word, banglishCandidates) {
+			alternatives = append(alternatives, "'"+c+"':*")
+		}
+
+		b.WriteString(join)
+		if negate {
+			b.WriteString("!")
+		}
+		b.WriteString("(" + strings.Join(alternatives, " | ") + ")")
+		join = " & "
+	}
+	return b.String()
+}
+
+// searchScope restricts a query to published articles matching q, joining
+// the parsed tsquery under the name "query".
+func (s *articleService) searchScope(q string) func(*gorm.DB) *gorm.DB {
+	expr, args := tsQuery(q)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("CROSS JOIN "+expr+" AS query", args...).
+			Where("articles.search_vector @@ query")
+	}
+}
+
+func (s *articleService) Search(params *validation.QuerySearch) ([]model.SearchResult, int64, error) {
+	offset := paginate(&params.Page, &params.Limit, 10, 50)
+
+	query := s.db.Model(&model.Article{}).Scopes(published, s.searchScope(params.Q))
+	query, err := s.filterCategory(query, params.Category)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Reusable for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.SearchResult
+	err = query.
+		Select(`articles.*,
+			ts_rank(articles.search_vector, query)
+				* (1 + ? * exp(-extract(epoch FROM now() - articles.created_at) / 86400 / ?)) AS rank,
+			ts_headline('simple', `+fmt.Sprintf(escapeHTMLSQL, "articles.title")+`, query, 'HighlightAll=true') AS title_highlight,
+			ts_headline('simple', `+fmt.Sprintf(escapeHTMLSQL, fmt.Sprintf(stripTagsSQL, "articles.content"))+`, query, ?) AS snippet`,
+			searchRecencyBoost, searchRecencyDays, headlineOptions).
+		Order("rank desc, articles.id desc").
+		Limit(params.Limit).
+		Offset(offset).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	refs := make([]*model.Article, len(items))
+	for i := range items {
+		refs[i] = &items[i].Article
+	}
+	attachImages(s.db, refs...)
+	return items, total, nil
+}
@@ -1,112 +1,293 @@
 package service
 
 import (
+	"app/src/config"
 	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ArticleService interface {
-	CreateArticle(a *model.Article) (*model.Article, error)
-	GetAllArticles(category, search string, limit int) ([]model.Article, error)
+	CreateArticle(a *model.Article, actor *model.User) (*model.Article, error)
+	GetAllArticles(params *validation.QueryArticle) ([]model.Article, int64, string, error)
 	GetByID(id int) (*model.Article, error)
-	DeleteByID(id string) error
-	GetFeatured(category string, limit int) ([]model.Article, error)
-	GetCategories() ([]string, error)
-	UpdateArticle(a *model.Article) (*model.Article, error)
+	GetPublishedByID(id int) (*model.Article, error)
+	GetPublishedBySlug(slug string) (*model.Article, string, error)
+	DeleteByID(id string, actor *model.User) error
+	GetTrash(params *validation.QueryTrash) ([]model.Article, int64, error)
+	Restore(id int, actor *model.User) (*model.Article, error)
+	Purge(id int) error
+	Bulk(req *validation.BulkArticles, actor *model.User) (*model.BulkResult, error)
+	GetFeatured(params *validation.QueryArticle) ([]model.Article, int64, string, error)
+	GetRelated(id, limit int) ([]model.RelatedArticle, error)
+	UpdateArticle(a *model.Article, actor *model.User, omitted ...string) (*model.Article, error)
+	GetDashboardArticles(status, category, search string, limit int) ([]model.Article, error)
+	Search(params *validation.QuerySearch) ([]model.SearchResult, int64, error)
+	TransitionArticle(req *validation.ArticleTransition, actor *model.User) (*model.Article, error)
+	GetTransitions(id int) ([]model.ArticleTransition, error)
+	GetRevisions(id int) ([]model.ArticleRevision, error)
+	DiffRevisions(id, from, to int) (*RevisionDiff, error)
+	RestoreRevision(req *validation.RestoreRevision, actor *model.User) (*model.Article, error)
+}
+
+// StaleArticleError is returned by UpdateArticle and RestoreRevision when the
+// caller worked on an outdated version. Current holds the server copy so the
+// client can merge.
+type StaleArticleError struct {
+	Current *model.Article
+}
+
+func (e *StaleArticleError) Error() string {
+	return fmt.Sprintf("article %d is at version %d", e.Current.ID, e.Current.Version)
 }
 
 type articleService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	related relatedCache
 }
 
 func NewArticleService(db *gorm.DB) ArticleService {
 	return &articleService{db: db}
 }
 
-func (s *articleService) CreateArticle(a *model.Article) (*model.Article, error) {
+func (s *articleService) CreateArticle(a *model.Article, actor *model.User) (*model.Article, error) {
+	if err := checkEmbargo(a.PublishAt, a.ExpireAt); err != nil {
+		return nil, err
+	}
+
+	if err := assignCategory(s.db, a); err != nil {
+		return nil, err
+	}
+
+	normalizeArticle(a)
 	a.UpdatedAt = time.Now()
-	if err := s.db.Create(a).Error; err != nil {
+	// Every story enters the workflow as a draft, whatever the client sent
+	a.Status = model.ArticleStatusDraft
+	a.Slug = ""
+	a.ViewCount = 0
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := resolveTags(tx, a.Tags)
+		if err != nil {
+			return err
+		}
+		if err := tx.Omit("Tags").Create(a).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(a).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		if err := assignSlug(tx, a); err != nil {
+			return err
+		}
+		if err := saveRevision(tx, a, actor, nil); err != nil {
+			return err
+		}
+		return tx.Create(&model.ArticleTransition{
+			ArticleID: a.ID,
+			Action:    "create",
+			ToStatus:  a.Status,
+			ActorID:   actorID(actor),
+			ActorName: actorName(actor),
+		}).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
-func (s *articleService) UpdateArticle(a *model.Article) (*model.Article, error) {
-	a.UpdatedAt = time.Now()
-	// Using Save or Select("*") is necessary to update boolean fields to false (zero values)
-	if err := s.db.Model(&model.Article{}).Where("id = ?", a.ID).Select("*").Updates(a).Error; err != nil {
+// UpdateArticle saves an edit. publish_at and expire_at named in omitted,
+// because the request left them out, keep their current values; sent as
+// null they clear the embargo.
+func (s *articleService) UpdateArticle(a *model.Article, actor *model.User, omitted ...string) (*model.Article, error) {
+	if err := assignCategory(s.db, a); err != nil {
 		return nil, err
 	}
-	return s.GetByID(a.ID)
-}
 
-func (s *articleService) GetAllArticles(category, search string, limit int) ([]model.Article, error) {
-	var items []model.Article
-	query := s.db.Order("updated_at desc")
+	normalizeArticle(a)
+	a.UpdatedAt = time.Now()
 
-	// Debug logging
-	println("=== GetAllArticles Debug ===")
-	println("Category received:", category)
-	println("Category length:", len(category))
-	println("Category bytes:", []byte(category))
+	var updated model.Article
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&updated, a.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "article not found")
+			}
+			return err
+		}
+		if err := checkEditable(&updated, actor); err != nil {
+			return err
+		}
 
-	if category != "" && category != "সব" {
-		println("Applying category filter:", category)
-		query = query.Where("category = ?", category)
-	} else {
-		println("No category filter applied (showing all)")
-	}
+		// A zero version comes from If-Match: * and overwrites any version
+		if a.Version != 0 && a.Version != updated.Version {
+			return &StaleArticleError{Current: &updated}
+		}
+		a.Version = updated.Version + 1
 
-	if search != "" {
-		// Use ILIKE for case-insensitive search in Postgres
-		query = query.Where("title ILIKE ? OR content ILIKE ?", "%"+search+"%", "%"+search+"%")
-	}
+		for _, field := range omitted {
+			switch field {
+			case "publish_at":
+				a.PublishAt = updated.PublishAt
+			case "expire_at":
+				a.ExpireAt = updated.ExpireAt
+			}
+		}
+		if err := checkEmbargo(a.PublishAt, a.ExpireAt); err != nil {
+			return err
+		}
 
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
+		// Select is necessary to update boolean fields to false (zero values).
+		// Status and created_at are deliberately left out: the status only changes
+		// through TransitionArticle.
+		err := tx.Model(&model.Article{}).
+			Where("id = ?", a.ID).
+			Select("title", "content", "category", "category_id", "author", "image", "featured", "publish_at", "expire_at", "version", "updated_at").
+			Updates(a).Error
+		if err != nil {
+			return err
+		}
 
-	if err := query.Find(&items).Error; err != nil {
+		// A missing tags field leaves the tags alone; an empty list clears them
+		if a.Tags != nil {
+			tags, err := resolveTags(tx, a.Tags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Article{ID: a.ID}).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Preload("Tags").First(&updated, a.ID).Error; err != nil {
+			return err
+		}
+		if err := assignSlug(tx, &updated); err != nil {
+			return err
+		}
+		return saveRevision(tx, &updated, actor, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	println("Articles found:", len(items))
-	for i, item := range items {
-		println("Article", i, "- Category:", item.Category)
+	touchArticles()
+	return &updated, nil
+}
+
+func (s *articleService) GetAllArticles(params *validation.QueryArticle) ([]model.Article, int64, string, error) {
+	query, err := s.filterCategory(s.db.Scopes(published), params.Category)
+	if err != nil {
+		return nil, 0, "", err
 	}
-	println("=========================")
 
-	return items, nil
+	if params.Search != "" {
+		// Full-text match; /search additionally ranks and highlights
+		query = query.Scopes(s.searchScope(params.Search))
+	}
+
+	return listArticles(query, params)
 }
 
 func (s *articleService) GetByID(id int) (*model.Article, error) {
 	var a model.Article
-	if err := s.db.First(&a, id).Error; err != nil {
+	if err := s.db.Preload("Tags").First(&a, id).Error; err != nil {
 		return nil, err
 	}
+	attachImages(s.db, &a)
 	return &a, nil
 }
 
-func (s *articleService) DeleteByID(id string) error {
-	// GORM Delete needs the primary key to be specified if not using a pointer with ID
-	return s.db.Where("id = ?", id).Delete(&model.Article{}).Error
+func (s *articleService) GetPublishedByID(id int) (*model.Article, error) {
+	var a model.Article
+	if err := s.db.Scopes(published).Preload("Tags").First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	attachImages(s.db, &a)
+	return &a, nil
+}
+
+func (s *articleService) GetFeatured(params *validation.QueryArticle) ([]model.Article, int64, string, error) {
+	query, err := s.filterCategory(s.db.Scopes(published).Where("featured = ?", true), params.Category)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	return listArticles(query, params)
+}
+
+// filterCategory limits query to a category given by slug, ID or Bengali
+// name, including all of its sub-categories. "সব" (all) applies no filter.
+func (s *articleService) filterCategory(query *gorm.DB, category string) (*gorm.DB, error) {
+	return filterCategory(s.db, query, category)
+}
+
+// filterCategory limits query to articles in category or its subcategories.
+// An empty category or "সব" (all) leaves the query untouched.
+func filterCategory(db, query *gorm.DB, category string) (*gorm.DB, error) {
+	if category == "" || category == "সব" {
+		return query, nil
+	}
+
+	ids, err := categoryTreeIDs(db, category)
+	if err != nil {
+		return nil, err
+	}
+	return query.Where("articles.category_id IN ?", ids), nil
+}
+
+// normalizeArticle stores text in NFC so search and diffs see identical
+// Bengali spellings as identical.
+func normalizeArticle(a *model.Article) {
+	a.Title = utils.NormalizeText(a.Title)
+	a.Content = utils.NormalizeText(a.Content)
+}
+
+// assignCategory checks that the article's category exists and fills both
+// CategoryID and the denormalised Bengali name. CategoryID wins when set.
+func assignCategory(db *gorm.DB, a *model.Article) error {
+	ref := a.Category
+	if a.CategoryID != nil && *a.CategoryID > 0 {
+		ref = strconv.Itoa(*a.CategoryID)
+	}
+	if ref == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "category is required")
+	}
+
+	cat, err := findCategory(db, ref)
+	if err != nil {
+		return err
+	}
+
+	a.CategoryID = &cat.ID
+	a.Category = cat.NameBn
+	return nil
 }
 
-func (s *articleService) GetFeatured(category string, limit int) ([]model.Article, error) {
+func (s *articleService) GetDashboardArticles(status, category, search string, limit int) ([]model.Article, error) {
 	var items []model.Article
-	query := s.db.Where("featured = ?", true).Order("updated_at desc")
+	query := s.db.Preload("Tags").Order("updated_at desc")
 
-	// Debug logging
-	println("=== GetFeatured Debug ===")
-	println("Category received:", category)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query, err := s.filterCategory(query, category)
+	if err != nil {
+		return nil, err
+	}
 
-	if category != "" && category != "সব" {
-		println("Applying category filter:", category)
-		query = query.Where("category = ?", category)
-	} else {
-		println("No category filter applied (showing all featured)")
+	if search != "" {
+		query = query.Where("title ILIKE ? OR content ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
 	if limit > 0 {
@@ -116,24 +297,96 @@ func (s *articleService) GetFeatured(category string, limit int) ([]model.Articl
 	if err := query.Find(&items).Error; err != nil {
 		return nil, err
 	}
+	attachImages(s.db, articleRefs(items)...)
+	return items, nil
+}
 
-	println("Featured articles found:", len(items))
-	println("======================")
+func (s *articleService) TransitionArticle(req *validation.ArticleTransition, actor *model.User) (*model.Article, error) {
+	action, ok := articleActions[req.Action]
+	if !ok {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "unknown action")
+	}
 
-	return items, nil
+	if actor == nil || !config.HasRights(actor.Role, action.right) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "You don't have permission to "+req.Action+" articles")
+	}
+
+	if action.needsNote && req.Note == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "a note is required to "+req.Action+" an article")
+	}
+
+	if err := checkEmbargo(req.PublishAt, req.ExpireAt); err != nil {
+		return nil, err
+	}
+
+	var article model.Article
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so two editors cannot move the same story concurrently
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&article, req.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "article not found")
+			}
+			return err
+		}
+
+		return applyTransition(tx, &article, action, req, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	touchArticles()
+	return &article, nil
 }
 
-func (s *articleService) GetCategories() ([]string, error) {
-	// Syncing with the Admin Panel list as requested
-	categories := []string{
-		"জাতীয়",
-		"রাজনীতি",
-		"খেলাধুলা",
-		"প্রযুক্তি",
-		"স্বাস্থ্য",
-		"বিনোদন",
-		"আন্তর্জাতিক",
-		"অন্যান্য",
+// applyTransition moves a locked article through action inside tx and logs
+// the step. Rights and the note are checked by the caller.
+func applyTransition(tx *gorm.DB, article *model.Article, action articleAction, req *validation.ArticleTransition, actor *model.User) error {
+	if !action.allowedFrom(article.Status) {
+		return fiber.NewError(fiber.StatusConflict, "cannot "+req.Action+" an article in "+article.Status+" state")
 	}
-	return categories, nil
+
+	from := article.Status
+	updates := map[string]interface{}{"status": action.to}
+
+	if req.Action == "publish" {
+		if req.PublishAt != nil {
+			article.PublishAt = req.PublishAt
+			updates["publish_at"] = req.PublishAt
+		}
+		if req.ExpireAt != nil {
+			article.ExpireAt = req.ExpireAt
+			updates["expire_at"] = req.ExpireAt
+		}
+		if err := checkEmbargo(article.PublishAt, article.ExpireAt); err != nil {
+			return err
+		}
+		// The scheduler flips it to published once publish_at is reached
+		if article.PublishAt != nil && article.PublishAt.After(time.Now()) {
+			updates["status"] = model.ArticleStatusScheduled
+		}
+	}
+
+	if err := tx.Model(article).Updates(updates).Error; err != nil {
+		return err
+	}
+	article.Status = updates["status"].(string)
+
+	return tx.Create(&model.ArticleTransition{
+		ArticleID:  article.ID,
+		Action:     req.Action,
+		FromStatus: from,
+		ToStatus:   article.Status,
+		Note:       req.Note,
+		ActorID:    actorID(actor),
+		ActorName:  actorName(actor),
+	}).Error
+}
+
+func (s *articleService) GetTransitions(id int) ([]model.ArticleTransition, error) {
+	var items []model.ArticleTransition
+	if err := s.db.Where("article_id = ?", id).Order("created_at asc, id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
 }
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// slugTaken reports whether slug is, or used to be, the slug of another article.
+func slugTaken(tx *gorm.DB, slug string, articleID int) (bool, error) {
+	var count int64
+	err := tx.Raw(`
+		SELECT (SELECT COUNT(*) FROM articles WHERE slug = ? AND id <> ?)
+		     + (SELECT COUNT(*) FROM article_slugs WHERE slug = ? AND article_id <> ?)`,
+		slug, articleID, slug, articleID).Scan(&count).Error
+	return count > 0, err
+}
+
+// assignSlug derives the article's slug from its title. The title slug is
+// used as is when free and suffixed with the article ID otherwise. A slug
+// that is replaced moves to the history table so old links keep working.
+func assignSlug(tx *gorm.DB, a *model.Article) error {
+	base := utils.ArticleSlug(a.Title)
+
+	slug := base
+	taken, err := slugTaken(tx, slug, a.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		slug = fmt.Sprintf("%s-%d", base, a.ID)
+	}
+
+	if slug == a.Slug {
+		return nil
+	}
+
+	if a.Slug != "" {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.ArticleSlug{ArticleID: a.ID, Slug: a.Slug}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// The article may be getting an old slug of its own back
+	if err := tx.Where("article_id = ? AND slug = ?", a.ID, slug).Delete(&model.ArticleSlug{}).Error; err != nil {
+		return err
+	}
+
+	a.Slug = slug
+	return tx.Model(&model.Article{}).Where("id = ?", a.ID).UpdateColumn("slug", slug).Error
+}
+
+// GetPublishedBySlug finds a published article by its current slug. For a
+// former slug it returns the article's current slug as redirect instead.
+func (s *articleService) GetPublishedBySlug(slug string) (*model.Article, string, error) {
+	var a model.Article
+	err := s.db.Scopes(published).Preload("Tags").Where("slug = ?", slug).First(&a).Error
+	if err == nil {
+		attachImages(s.db, &a)
+		return &a, "", nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", err
+	}
+
+	var current []string
+	err = s.db.Model(&model.Article{}).
+		Scopes(published).
+		Joins("JOIN article_slugs ON article_slugs.article_id = articles.id").
+		Where("article_slugs.slug = ?", slug).
+		Pluck("articles.slug", &current).Error
+	if err != nil {
+		return nil, "", err
+	}
+	if len(current) == 0 {
+		return nil, "", fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	return nil, current[0], nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/validation"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purgeBatchSize bounds how many expired articles one scheduler tick purges.
+const purgeBatchSize = 100
+
+// DeleteByID moves an article to the trash. It disappears from every reader
+// and dashboard listing but can be restored until it is purged.
+func (s *articleService) DeleteByID(id string, actor *model.User) error {
+	articleID, err := strconv.Atoi(id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var a model.Article
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&a, articleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "article not found")
+			}
+			return err
+		}
+
+		return trashArticle(tx, &a, actor)
+	})
+	if err != nil {
+		return err
+	}
+
+	touchArticles()
+	return nil
+}
+
+// trashArticle soft-deletes a locked article inside tx and logs it.
+func trashArticle(tx *gorm.DB, a *model.Article, actor *model.User) error {
+	if err := tx.Model(a).UpdateColumn("deleted_by", actorID(actor)).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(a).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.ArticleTransition{
+		ArticleID:  a.ID,
+		Action:     "delete",
+		FromStatus: a.Status,
+		ToStatus:   a.Status,
+		ActorID:    actorID(actor),
+		ActorName:  actorName(actor),
+	}).Error
+}
+
+// GetTrash lists deleted articles, most recently deleted first.
+func (s *articleService) GetTrash(params *validation.QueryTrash) ([]model.Article, int64, error) {
+	offset := paginate(&params.Page, &params.Limit, 20, 100)
+
+	query := s.db.Unscoped().Model(&model.Article{}).Where("deleted_at IS NOT NULL")
+	if params.Search != "" {
+		query = query.Where("title ILIKE ?", "%"+params.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.Article
+	err := query.Order("deleted_at desc, id desc").Offset(offset).Limit(params.Limit).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Restore takes an article out of the trash in the state it was deleted in.
+func (s *articleService) Restore(id int, actor *model.User) (*model.Article, error) {
+	var a model.Article
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL").
+			First(&a, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "article not found in trash")
+			}
+			return err
+		}
+
+		err = tx.Unscoped().Model(&a).
+			Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.ArticleTransition{
+			ArticleID:  a.ID,
+			Action:     "restore",
+			FromStatus: a.Status,
+			ToStatus:   a.Status,
+			ActorID:    actorID(actor),
+			ActorName:  actorName(actor),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	touchArticles()
+	return s.GetByID(id)
+}
+
+// Purge deletes a trashed article and everything recorded about it for good.
+// Articles have to be in the trash first.
+func (s *articleService) Purge(id int) error {
+	var count int64
+	err := s.db.Unscoped().Model(&model.Article{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "article not found in trash")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return purgeArticles(tx, []int{id})
+	})
+}
+
+// purgeArticles removes trashed articles with their tags, slugs, history,
+// statistics and live blogs.
+func purgeArticles(tx *gorm.DB, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	statements := []string{
+		"DELETE FROM article_tags WHERE article_id IN ?",
+		"DELETE FROM article_slugs WHERE article_id IN ?",
+		"DELETE FROM article_revisions WHERE article_id IN ?",
+		"DELETE FROM article_transitions WHERE article_id IN ?",
+		"DELETE FROM article_visits WHERE article_id IN ?",
+		"DELETE FROM article_view_stats WHERE article_id IN ?",
+		"DELETE FROM article_share_stats WHERE article_id IN ?",
+		"DELETE FROM trending_entries WHERE article_id IN ?",
+		"DELETE FROM live_blog_entries WHERE live_blog_id IN (SELECT id FROM live_blogs WHERE article_id IN ?)",
+		"DELETE FROM live_blogs WHERE article_id IN ?",
+		"DELETE FROM articles WHERE id IN ? AND deleted_at IS NOT NULL",
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, ids).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeTrash purges articles that have been in the trash longer than the
+// configured retention, a batch at a time.
+func purgeTrash(ctx context.Context, db *gorm.DB) (int, error) {
+	if config.TrashRetention <= 0 {
+		return 0, nil
+	}
+
+	var purged []int
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&model.Article{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("deleted_at < ?", time.Now().Add(-config.TrashRetention)).
+			Order("deleted_at").
+			Limit(purgeBatchSize).
+			Pluck("id", &purged).Error
+		if err != nil {
+			return err
+		}
+		return purgeArticles(tx, purged)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(purged), nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ArticleURL is the public, server-rendered address of an article, as used
+// in feeds, sitemaps and share links.
+func ArticleURL(a *model.Article) string {
+	if a.Slug == "" {
+		return ArticlePermalink(a)
+	}
+	return config.AppURL + "/news/" + url.PathEscape(a.Slug)
+}
+
+// ArticlePermalink is an address of an article that never changes, unlike
+// ArticleURL, whose slug follows the headline. Feeds identify items by it, so
+// readers do not show a retitled story twice.
+func ArticlePermalink(a *model.Article) string {
+	return config.AppURL + "/?article=" + strconv.Itoa(a.ID)
+}
+
+// AbsoluteURL makes a site-relative path such as an uploaded image's
+// absolute.
+func AbsoluteURL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return config.AppURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// CategoryURL is the public address of a category's front page.
+func CategoryURL(c *model.Category) string {
+	return config.AppURL + "/category/" + url.PathEscape(c.Slug)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BreakingNewsTopic is the stream the ticker listens on. Events are "add"
+// with the item, "remove" with its id, and, sent by the stream endpoint
+// itself, "snapshot" with every live item.
+const BreakingNewsTopic = "breaking"
+
+type BreakingNewsService interface {
+	GetLive() ([]model.BreakingNews, error)
+	GetAll(params *validation.QueryBreakingNews) ([]model.BreakingNews, int64, error)
+	Create(req *validation.CreateBreakingNews, actor *model.User) (*model.BreakingNews, error)
+	Expire(id int, actor *model.User) (*model.BreakingNews, error)
+	Sync(ctx context.Context) error
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type breakingNewsService struct {
+	db *gorm.DB
+}
+
+func NewBreakingNewsService(db *gorm.DB) BreakingNewsService {
+	return &breakingNewsService{db: db}
+}
+
+type breakingNewsRemoved struct {
+	ID int `json:"id"`
+}
+
+// GetLive returns the items on the ticker, most important first.
+func (s *breakingNewsService) GetLive() ([]model.BreakingNews, error) {
+	items := []model.BreakingNews{}
+	err := s.db.Where("status = ?", model.BreakingNewsLive).
+		Order("priority desc, start_at desc, id desc").
+		Find(&items).Error
+	return items, err
+}
+
+func (s *breakingNewsService) GetAll(params *validation.QueryBreakingNews) ([]model.BreakingNews, int64, error) {
+	offset := paginate(&params.Page, &params.Limit, 20, 100)
+
+	query := s.db.Model(&model.BreakingNews{})
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.BreakingNews
+	err := query.Order("created_at desc, id desc").Offset(offset).Limit(params.Limit).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Create adds an item. Without a start time it goes live at once; a future
+// one waits for Sync.
+func (s *breakingNewsService) Create(req *validation.CreateBreakingNews, actor *model.User) (*model.BreakingNews, error) {
+	now := time.Now()
+	item := &model.BreakingNews{
+		Headline: strings.TrimSpace(req.Headline),
+		Priority: req.Priority,
+		Status:   model.BreakingNewsScheduled,
+		StartAt:  now,
+		EndAt:    req.EndAt,
+	}
+	if item.Headline == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "headline is required")
+	}
+	if req.Link != nil && strings.TrimSpace(*req.Link) != "" {
+		link := strings.TrimSpace(*req.Link)
+		if !isSafeLink(link) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "link must be a site path or an http(s) URL")
+		}
+		item.Link = &link
+	}
+	if req.StartAt != nil {
+		item.StartAt = *req.StartAt
+	}
+	if item.EndAt != nil && !item.EndAt.After(item.StartAt) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "end_at must be after start_at")
+	}
+	if item.EndAt != nil && !item.EndAt.After(now) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "end_at is already in the past")
+	}
+	if actor != nil {
+		item.CreatedBy = &actor.ID
+	}
+	if !item.StartAt.After(now) {
+		item.Status = model.BreakingNewsLive
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		if item.Status == model.BreakingNewsLive {
+			return publishEvent(tx, BreakingNewsTopic, "add", item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Expire takes an item off the ticker now, or cancels it if it has not
+// started yet.
+func (s *breakingNewsService) Expire(id int, actor *model.User) (*model.BreakingNews, error) {
+	var item model.BreakingNews
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "breaking news not found")
+			}
+			return err
+		}
+		if item.Status == model.BreakingNewsExpired {
+			return fiber.NewError(fiber.StatusConflict, "breaking news has already expired")
+		}
+
+		wasLive := item.Status == model.BreakingNewsLive
+		now := time.Now()
+		updates := map[string]interface{}{"status": model.BreakingNewsExpired, "end_at": now}
+		if actor != nil {
+			updates["expired_by"] = actor.ID
+		}
+		if err := tx.Model(&item).Updates(updates).Error; err != nil {
+			return err
+		}
+		item.Status = model.BreakingNewsExpired
+		item.EndAt = &now
+		if actor != nil {
+			item.ExpiredBy = &actor.ID
+		}
+		if wasLive {
+			return publishEvent(tx, BreakingNewsTopic, "remove", breakingNewsRemoved{ID: item.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Sync ends items whose end_at has passed and starts those whose start_at
+// has come, announcing each change. Like ArticleScheduler, each change is a
+// conditional UPDATE, so running it in several processes is harmless.
+func (s *breakingNewsService) Sync(ctx context.Context) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ended []model.BreakingNews
+		err := tx.Model(&ended).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("status = ? AND end_at <= now()", model.BreakingNewsLive).
+			Update("status", model.BreakingNewsExpired).Error
+		if err != nil {
+			return err
+		}
+		for _, item := range ended {
+			if err := publishEvent(tx, BreakingNewsTopic, "remove", breakingNewsRemoved{ID: item.ID}); err != nil {
+				return err
+			}
+		}
+
+		// Missed entirely, e.g. while the server was down
+		err = tx.Model(&model.BreakingNews{}).
+			Where("status = ? AND end_at <= now()", model.BreakingNewsScheduled).
+			Update("status", model.BreakingNewsExpired).Error
+		if err != nil {
+			return err
+		}
+
+		var started []model.BreakingNews
+		err = tx.Model(&started).
+			Clauses(clause.Returning{}).
+			Where("status = ? AND start_at <= now()", model.BreakingNewsScheduled).
+			Update("status", model.BreakingNewsLive).Error
+		if err != nil {
+			return err
+		}
+		for i := range started {
+			if err := publishEvent(tx, BreakingNewsTopic, "add", &started[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Run syncs every interval until ctx is cancelled.
+func (s *breakingNewsService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			utils.Log.Errorf("Breaking news: sync failed: %+v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// isSafeLink accepts site paths and http(s) URLs, which are safe to put in
+// an href or src as they are.
+func isSafeLink(link string) bool {
+	if strings.HasPrefix(link, "//") {
+		return false
+	}
+	return strings.HasPrefix(link, "/") || strings.HasPrefix(link, "https://") || strings.HasPrefix(link, "http://")
+}
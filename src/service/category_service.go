@@ -0,0 +1,275 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CategoryService interface {
+	GetAll(includeInactive bool) ([]model.Category, error)
+	GetByID(id int) (*model.Category, error)
+	GetBySlug(slug string) (*model.Category, error)
+	Create(req *validation.CreateCategory) (*model.Category, error)
+	Update(id int, req *validation.UpdateCategory) (*model.Category, error)
+	Delete(id int) error
+}
+
+type categoryService struct {
+	db *gorm.DB
+}
+
+func NewCategoryService(db *gorm.DB) CategoryService {
+	return &categoryService{db: db}
+}
+
+func (s *categoryService) GetAll(includeInactive bool) ([]model.Category, error) {
+	var items []model.Category
+	query := s.db.Order("sort_order asc, id asc")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *categoryService) GetByID(id int) (*model.Category, error) {
+	var cat model.Category
+	if err := s.db.First(&cat, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "category not found")
+		}
+		return nil, err
+	}
+	return &cat, nil
+}
+
+// GetBySlug returns an active category; inactive ones are not public.
+func (s *categoryService) GetBySlug(slug string) (*model.Category, error) {
+	var cat model.Category
+	if err := s.db.Where("slug = ? AND is_active = ?", slug, true).First(&cat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "category not found")
+		}
+		return nil, err
+	}
+	return &cat, nil
+}
+
+func (s *categoryService) Create(req *validation.CreateCategory) (*model.Category, error) {
+	cat := model.Category{
+		Slug:      utils.Slugify(req.Slug),
+		NameBn:    req.NameBn,
+		NameEn:    req.NameEn,
+		ParentID:  req.ParentID,
+		SortOrder: req.SortOrder,
+		IsActive:  req.IsActive == nil || *req.IsActive,
+	}
+	if cat.Slug == "" {
+		cat.Slug = utils.Slugify(firstNonEmpty(cat.NameEn, cat.NameBn))
+	}
+
+	if cat.ParentID != nil {
+		if _, err := s.GetByID(*cat.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&cat).Error; err != nil {
+			return err
+		}
+		// is_active defaults to true in the database, so a false value is
+		// skipped by Create and has to be written explicitly
+		if !cat.IsActive {
+			return tx.Model(&cat).Update("is_active", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fiber.NewError(fiber.StatusConflict, "slug or name already exists")
+		}
+		return nil, err
+	}
+
+	touchArticles()
+	return &cat, nil
+}
+
+func (s *categoryService) Update(id int, req *validation.UpdateCategory) (*model.Category, error) {
+	cat, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Slug != nil {
+		updates["slug"] = utils.Slugify(*req.Slug)
+	}
+	if req.NameBn != nil {
+		updates["name_bn"] = *req.NameBn
+	}
+	if req.NameEn != nil {
+		updates["name_en"] = *req.NameEn
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if req.ParentID != nil {
+		// parent_id 0 moves the category to the top level
+		if *req.ParentID == 0 {
+			updates["parent_id"] = nil
+		} else {
+			if err := s.checkParent(id, *req.ParentID); err != nil {
+				return nil, err
+			}
+			updates["parent_id"] = *req.ParentID
+		}
+	}
+
+	if len(updates) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid Request: No fields to update")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(cat).Updates(updates).Error; err != nil {
+			return err
+		}
+		// Articles keep the Bengali name denormalised for older clients
+		if name, ok := updates["name_bn"]; ok {
+			return tx.Model(&model.Article{}).Where("category_id = ?", id).Update("category", name).Error
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fiber.NewError(fiber.StatusConflict, "slug or name already exists")
+		}
+		return nil, err
+	}
+
+	touchArticles()
+	return s.GetByID(id)
+}
+
+// checkParent refuses a parent that does not exist or would create a cycle.
+func (s *categoryService) checkParent(id, parentID int) error {
+	all, err := s.GetAll(true)
+	if err != nil {
+		return err
+	}
+
+	parents := make(map[int]*int, len(all))
+	for _, c := range all {
+		parents[c.ID] = c.ParentID
+	}
+
+	if _, ok := parents[parentID]; !ok {
+		return fiber.NewError(fiber.StatusNotFound, "parent category not found")
+	}
+
+	for cur := &parentID; cur != nil; cur = parents[*cur] {
+		if *cur == id {
+			return fiber.NewError(fiber.StatusConflict, "a category cannot be its own ancestor")
+		}
+	}
+	return nil
+}
+
+func (s *categoryService) Delete(id int) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+
+	var articles, children int64
+	if err := s.db.Model(&model.Article{}).Where("category_id = ?", id).Count(&articles).Error; err != nil {
+		return err
+	}
+	if err := s.db.Model(&model.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		return err
+	}
+	if articles > 0 || children > 0 {
+		return fiber.NewError(fiber.StatusConflict, "category still has articles or sub-categories; deactivate it instead")
+	}
+
+	if err := s.db.Delete(&model.Category{}, id).Error; err != nil {
+		return err
+	}
+
+	touchArticles()
+	return nil
+}
+
+// findCategory looks a category up by numeric ID, slug or Bengali name.
+func findCategory(db *gorm.DB, ref string) (*model.Category, error) {
+	var cat model.Category
+	query := db.Where("slug = ? OR name_bn = ?", ref, ref)
+	if id, err := strconv.Atoi(ref); err == nil {
+		query = db.Where("id = ?", id)
+	}
+
+	if err := query.First(&cat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "unknown category: "+ref)
+		}
+		return nil, err
+	}
+	return &cat, nil
+}
+
+// categoryTreeIDs resolves ref (see findCategory) to the category's ID and the
+// IDs of all of its descendants. An unknown category yields no IDs.
+func categoryTreeIDs(db *gorm.DB, ref string) ([]int, error) {
+	root, err := findCategory(db, ref)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return []int{}, nil
+		}
+		return nil, err
+	}
+
+	var all []model.Category
+	if err := db.Select("id", "parent_id").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]int)
+	for _, c := range all {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []int{root.ID}
+	seen := map[int]bool{root.ID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
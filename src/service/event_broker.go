@@ -0,0 +1,250 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stream events are read in pages of streamPollSize. A reader that falls
+// subscriberBuffer events behind is disconnected; its browser reconnects
+// and catches up from the database. Replays longer than maxReplay are
+// answered with a fresh snapshot instead.
+const (
+	streamPollSize   = 500
+	subscriberBuffer = 64
+	maxReplay        = 1000
+
+	// streamEventLock serialises publishers, see publishEvent
+	streamEventLock = 7716001
+)
+
+type EventBroker interface {
+	Subscribe(topic string) *Subscription
+	Since(topic string, afterID int64) ([]model.StreamEvent, bool, error)
+	LastID() (int64, error)
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// Subscription receives a topic's events until it is closed, or until the
+// broker closes Events because the reader fell behind or the server is
+// shutting down.
+type Subscription struct {
+	Events <-chan model.StreamEvent
+
+	events chan model.StreamEvent
+	topic  string
+	broker *eventBroker
+}
+
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+type eventBroker struct {
+	db        *gorm.DB
+	retention time.Duration
+
+	mu        sync.Mutex
+	subs      map[string]map[*Subscription]struct{}
+	closed    bool
+	lastID    int64
+	lastPrune time.Time
+}
+
+// NewEventBroker fans stream events out to this process's connections.
+// Events are kept in the database for retention so that readers can resume.
+func NewEventBroker(db *gorm.DB, retention time.Duration) EventBroker {
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+	return &eventBroker{
+		db:        db,
+		retention: retention,
+		subs:      make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// publishEvent adds an event inside the caller's transaction, so it is only
+// sent if the change it describes is committed. Publishers take a
+// transaction-level lock before the row gets its id, so ids become visible
+// in order and a reader polling for id > last never skips one.
+func publishEvent(tx *gorm.DB, topic, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", streamEventLock).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.StreamEvent{Topic: topic, Type: eventType, Data: string(payload)}).Error
+}
+
+func (b *eventBroker) Subscribe(topic string) *Subscription {
+	events := make(chan model.StreamEvent, subscriberBuffer)
+	sub := &Subscription{Events: events, events: events, topic: topic, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(events)
+		return sub
+	}
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[*Subscription]struct{})
+	}
+	b.subs[topic][sub] = struct{}{}
+	return sub
+}
+
+func (b *eventBroker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub.topic][sub]; ok {
+		delete(b.subs[sub.topic], sub)
+		close(sub.events)
+	}
+}
+
+// Since returns a topic's events after afterID, oldest first. It reports
+// false when some of them may have been pruned already (or there are too
+// many to replay), in which case the reader needs a snapshot.
+func (b *eventBroker) Since(topic string, afterID int64) ([]model.StreamEvent, bool, error) {
+	var oldest int64
+	if err := b.db.Model(&model.StreamEvent{}).Select("COALESCE(MIN(id), 0)").Scan(&oldest).Error; err != nil {
+		return nil, false, err
+	}
+	if oldest == 0 || oldest > afterID+1 {
+		return nil, false, nil
+	}
+
+	var events []model.StreamEvent
+	err := b.db.Where("topic = ? AND id > ?", topic, afterID).
+		Order("id").
+		Limit(maxReplay + 1).
+		Find(&events).Error
+	if err != nil {
+		return nil, false, err
+	}
+	if len(events) > maxReplay {
+		return nil, false, nil
+	}
+	return events, true, nil
+}
+
+// LastID is the id of the newest event, to tag a snapshot with.
+func (b *eventBroker) LastID() (int64, error) {
+	var id int64
+	err := b.db.Model(&model.StreamEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// Run polls for new events every interval and hands them to subscribers
+// until ctx is cancelled, then closes every subscription so open streams
+// end and the server can shut down.
+func (b *eventBroker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer b.close()
+
+	last, err := b.LastID()
+	if err != nil {
+		utils.Log.Errorf("Event broker: could not read the last event: %+v", err)
+	}
+	b.mu.Lock()
+	b.lastID = last
+	b.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := b.poll(ctx); err != nil {
+			utils.Log.Errorf("Event broker: poll failed: %+v", err)
+		}
+		if err := b.prune(ctx); err != nil {
+			utils.Log.Errorf("Event broker: prune failed: %+v", err)
+		}
+	}
+}
+
+func (b *eventBroker) poll(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		last := b.lastID
+		b.mu.Unlock()
+
+		var events []model.StreamEvent
+		err := b.db.WithContext(ctx).
+			Where("id > ?", last).
+			Order("id").
+			Limit(streamPollSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		b.dispatch(events)
+		if len(events) < streamPollSize {
+			return nil
+		}
+	}
+}
+
+func (b *eventBroker) dispatch(events []model.StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, event := range events {
+		for sub := range b.subs[event.Topic] {
+			select {
+			case sub.events <- event:
+			default:
+				// Too slow; it resumes from its last event on reconnect
+				delete(b.subs[event.Topic], sub)
+				close(sub.events)
+			}
+		}
+		b.lastID = event.ID
+	}
+}
+
+// prune drops events past the retention period, at most once an hour. Every
+// process does this; the deletes are idempotent.
+func (b *eventBroker) prune(ctx context.Context) error {
+	now := time.Now()
+	b.mu.Lock()
+	due := now.Sub(b.lastPrune) >= time.Hour
+	if due {
+		b.lastPrune = now
+	}
+	b.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	return b.db.WithContext(ctx).
+		Where("created_at < ?", now.Add(-b.retention)).
+		Delete(&model.StreamEvent{}).Error
+}
+
+func (b *eventBroker) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for topic, subs := range b.subs {
+		for sub := range subs {
+			close(sub.events)
+		}
+		delete(b.subs, topic)
+	}
+}
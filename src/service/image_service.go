@@ -0,0 +1,332 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/storage"
+	"app/src/utils"
+	"app/src/validation"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Uploads of up to inlineVariantPixels get their variants before the
+	// upload request returns; larger ones are processed in the background
+	// and their status is polled
+	inlineVariantPixels = 2_000_000
+
+	// A media file left processing this long, by a process that has since
+	// died, is picked up again
+	staleProcessing = 10 * time.Minute
+)
+
+type ImageService interface {
+	Upload(file *multipart.FileHeader, details *validation.MediaDetails, actor *model.User) (*model.UploadedImage, error)
+	Store(data []byte, details *validation.MediaDetails, actor *model.User) (*model.UploadedImage, error)
+	Get(id int) (*model.UploadedImage, error)
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type imageService struct {
+	db    *gorm.DB
+	files storage.Storage
+	wake  chan struct{}
+}
+
+func NewImageService(db *gorm.DB, files storage.Storage) ImageService {
+	return &imageService{db: db, files: files, wake: make(chan struct{}, 1)}
+}
+
+// Upload checks that file is an image of an accepted format and size by its
+// content, strips its metadata and stores it under the SHA-256 of what is
+// left. The same picture uploaded twice is stored once. Resized variants are
+// made right away for small images and in the background for large ones.
+// details describe a new picture; for one uploaded before they only fill in
+// what was left blank.
+func (s *imageService) Upload(file *multipart.FileHeader, details *validation.MediaDetails, actor *model.User) (*model.UploadedImage, error) {
+	if file.Size > config.UploadMaxBytes {
+		return nil, tooLarge()
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, config.UploadMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	return s.Store(data, details, actor)
+}
+
+// Store does the work of Upload for a file already read, such as a finished
+// resumable upload.
+func (s *imageService) Store(data []byte, details *validation.MediaDetails, actor *model.User) (*model.UploadedImage, error) {
+	if int64(len(data)) > config.UploadMaxBytes {
+		return nil, tooLarge()
+	}
+
+	format := utils.SniffImage(data)
+	if format == "" {
+		return nil, fiber.NewError(fiber.StatusUnsupportedMediaType, "only JPEG, PNG, WebP, GIF and AVIF images can be uploaded")
+	}
+
+	width, height, err := utils.ImageSize(data, format)
+	if err != nil || width <= 0 || height <= 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the image is damaged or incomplete")
+	}
+	if width > config.UploadMaxWidth || height > config.UploadMaxHeight {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf(
+			"the image is %dx%d pixels; the largest allowed is %dx%d",
+			width, height, config.UploadMaxWidth, config.UploadMaxHeight))
+	}
+	if width*height > config.UploadMaxPixels {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf(
+			"the image has %.1f megapixels; the largest allowed is %.1f",
+			float64(width*height)/1e6, float64(config.UploadMaxPixels)/1e6))
+	}
+
+	data, err = utils.StripImageMetadata(data, format)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the image is damaged or incomplete")
+	}
+	// Record the size the picture is shown at
+	if utils.ImageOrientation(data, format) >= 5 {
+		width, height = height, width
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:]) + "." + format
+	duplicate, err := storeUpload(context.Background(), s.files, name, bytes.NewReader(data), int64(len(data)), utils.ImageMIME[format])
+	if err != nil {
+		return nil, err
+	}
+
+	media := &model.Media{
+		File:   name,
+		URL:    "/uploads/" + name,
+		Type:   utils.ImageMIME[format],
+		Width:  width,
+		Height: height,
+		Size:   int64(len(data)),
+		Status: model.MediaPending,
+	}
+	if actor != nil {
+		media.UploadedBy, media.UploaderName = &actor.ID, actor.Name
+	}
+	media, err = recordMedia(s.db, media, details)
+	if err != nil {
+		return nil, err
+	}
+
+	if media.Status == model.MediaPending {
+		claimed := false
+		if width*height <= inlineVariantPixels {
+			result := s.db.Model(media).Where("status = ?", model.MediaPending).Update("status", model.MediaProcessing)
+			if result.Error != nil {
+				return nil, result.Error
+			}
+			claimed = result.RowsAffected == 1
+		}
+		if claimed {
+			if err := s.process(context.Background(), media); err != nil {
+				return nil, err
+			}
+		} else {
+			s.notify()
+		}
+	}
+
+	return uploadedImage(media, duplicate), nil
+}
+
+func tooLarge() error {
+	return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf(
+		"the image is larger than %.0f MB", float64(config.UploadMaxBytes)/(1<<20)))
+}
+
+// recordMedia adds a media row for a stored file, or returns the one there
+// is with its blank details filled in. A file whose processing failed is
+// queued again.
+func recordMedia(db *gorm.DB, media *model.Media, details *validation.MediaDetails) (*model.Media, error) {
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(media).Error; err != nil {
+		return nil, err
+	}
+
+	var stored model.Media
+	if err := db.Preload("Variants").Where("file = ?", media.File).First(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	updates := mediaDetails(&stored, details, false)
+	if stored.Status == model.MediaFailed {
+		updates["status"], updates["error"] = model.MediaPending, ""
+		stored.Status, stored.Error = model.MediaPending, ""
+	}
+	if len(updates) > 0 {
+		if err := db.Model(&stored).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &stored, nil
+}
+
+// Get reports an upload and how far its variants are.
+func (s *imageService) Get(id int) (*model.UploadedImage, error) {
+	var media model.Media
+	if err := s.db.Preload("Variants").First(&media, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "image not found")
+		}
+		return nil, err
+	}
+	return uploadedImage(&media, false), nil
+}
+
+func uploadedImage(media *model.Media, duplicate bool) *model.UploadedImage {
+	image := &model.UploadedImage{
+		URL:       media.URL,
+		Name:      media.File,
+		Type:      media.Type,
+		Width:     media.Width,
+		Height:    media.Height,
+		Size:      media.Size,
+		Duplicate: duplicate,
+		Images:    media.ImageSet(),
+	}
+	if media.Status == model.MediaPending || media.Status == model.MediaProcessing {
+		image.StatusURL = "/api/images/" + strconv.Itoa(media.ID)
+	}
+	return image
+}
+
+// notify wakes this process's worker for a new upload. Workers in other
+// processes find it on their next poll.
+func (s *imageService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run makes the variants of queued uploads until ctx is cancelled. Every
+// process runs a worker; each upload is claimed by one of them.
+func (s *imageService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			media, err := s.claim(ctx)
+			if err != nil {
+				utils.Log.Errorf("Image worker: could not claim an upload: %+v", err)
+				break
+			}
+			if media == nil {
+				break
+			}
+			if err := s.process(ctx, media); err != nil {
+				utils.Log.Errorf("Image worker: could not record variants of %s: %+v", media.File, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// claim takes the oldest queued upload, or nil when there is none.
+func (s *imageService) claim(ctx context.Context) (*model.Media, error) {
+	var media model.Media
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND updated_at < ?)",
+				model.MediaPending, model.MediaProcessing, time.Now().Add(-staleProcessing)).
+			Order("id").
+			First(&media).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&media).Update("status", model.MediaProcessing).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// process makes the variants of a claimed upload and records them with the
+// outcome. Only a failure to record is returned; a picture that cannot be
+// processed is marked failed.
+func (s *imageService) process(ctx context.Context, media *model.Media) error {
+	variants, err := makeVariants(ctx, s.files, media)
+	if ctx.Err() != nil {
+		// Shutting down; a worker starts over after the restart
+		return s.db.Model(media).Update("status", model.MediaPending).Error
+	}
+	status, reason := model.MediaReady, ""
+	switch {
+	case errors.Is(err, errNoVariants):
+		status, reason = model.MediaSkipped, err.Error()
+	case err != nil:
+		status, reason = model.MediaFailed, err.Error()
+		utils.Log.Errorf("Image worker: could not make variants of %s: %+v", media.File, err)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", media.ID).Delete(&model.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		if len(variants) > 0 {
+			if err := tx.Create(&variants).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(media).Updates(map[string]interface{}{"status": status, "error": reason}).Error
+	})
+	if err != nil {
+		return err
+	}
+	media.Variants = variants
+	return nil
+}
+
+// storeUpload stores the size bytes of r as name unless a file of that name,
+// which has the same content, is already there. One old enough to be taken
+// for an orphan is written again, so the sweep does not remove it from under
+// this upload.
+func storeUpload(ctx context.Context, files storage.Storage, name string, r io.Reader, size int64, contentType string) (duplicate bool, err error) {
+	info, err := files.Stat(ctx, name)
+	switch {
+	case err == nil && (config.UploadOrphanGrace <= 0 || time.Since(info.ModTime) < config.UploadOrphanGrace/2):
+		return true, nil
+	case err == nil:
+		duplicate = true
+	case !errors.Is(err, fs.ErrNotExist):
+		return false, err
+	}
+	return duplicate, files.PutReader(ctx, name, r, size, contentType)
+}
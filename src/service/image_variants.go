@@ -0,0 +1,179 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/storage"
+	"app/src/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// imageVariants are made from every upload, narrowest first. Those without
+// a height keep the original's shape and are never made wider than it; the
+// Open Graph image is cropped to fill its frame, scaled down to fit a
+// smaller original.
+var imageVariants = []struct {
+	name          string
+	width, height int
+}{
+	{model.MediaThumbnail, 320, 0},
+	{model.MediaCard, 640, 0},
+	{model.MediaHero, 1280, 0},
+	{model.MediaOpenGraph, 1200, 630},
+}
+
+const (
+	variantJPEGQuality = 82
+	variantWebPQuality = 80
+)
+
+var errNoVariants = errors.New("variants are only made from JPEG, PNG, GIF and WebP images; the original is used as is")
+
+// webpEncoder is the path of cwebp, or "" when it is not installed and
+// WebP variants are left out.
+var webpEncoder = sync.OnceValue(func() string {
+	path, err := exec.LookPath(config.WebPEncoder)
+	if err != nil {
+		utils.Log.Warnf("WebP image variants are off: %s not found", config.WebPEncoder)
+		return ""
+	}
+	return path
+})
+
+// makeVariants resizes a stored upload into each of imageVariants, as JPEG
+// (PNG when it has transparency) and as WebP, and stores the files next to
+// the original as <hash>-<variant>.<format>.
+func makeVariants(ctx context.Context, files storage.Storage, media *model.Media) (variants []model.MediaVariant, err error) {
+	format := strings.TrimPrefix(filepath.Ext(media.File), ".")
+	if format != utils.ImageJPEG && format != utils.ImagePNG && format != utils.ImageGIF && format != utils.ImageWebP {
+		return nil, errNoVariants
+	}
+
+	// A damaged file must not take the worker down with it
+	defer func() {
+		if r := recover(); r != nil {
+			variants, err = nil, fmt.Errorf("decoding failed: %v", r)
+		}
+	}()
+
+	data, err := files.Get(ctx, media.File)
+	if err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	orientation := utils.ImageOrientation(data, format)
+	base := strings.TrimSuffix(media.File, filepath.Ext(media.File))
+
+	made := map[int]bool{}
+	for _, v := range imageVariants {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		width, height := v.width, v.height
+		if height == 0 {
+			width = min(width, media.Width)
+			// A small original needs fewer sizes
+			if made[width] {
+				continue
+			}
+			made[width] = true
+		} else if media.Width < width || media.Height < height {
+			// Keep the frame's shape without enlarging the original
+			scale := min(float64(media.Width)/float64(width), float64(media.Height)/float64(height))
+			width = max(1, int(float64(width)*scale))
+			height = max(1, int(float64(height)*scale))
+		}
+
+		img := utils.ResizeImage(src, orientation, width, height, height > 0)
+		encoded, ext, err := encodeVariant(img)
+		if err != nil {
+			return nil, err
+		}
+		variant, err := storeVariant(ctx, files, media, base, v.name, ext, encoded, img.Rect.Dx(), img.Rect.Dy())
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+
+		if webpEncoder() == "" {
+			continue
+		}
+		encoded, err = encodeWebP(ctx, img)
+		if err != nil {
+			return nil, err
+		}
+		variant, err = storeVariant(ctx, files, media, base, v.name, utils.ImageWebP, encoded, img.Rect.Dx(), img.Rect.Dy())
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+func storeVariant(ctx context.Context, files storage.Storage, media *model.Media, base, name, ext string, data []byte, width, height int) (model.MediaVariant, error) {
+	file := base + "-" + name + "." + ext
+	if err := files.Put(ctx, file, data, utils.ImageMIME[ext]); err != nil {
+		return model.MediaVariant{}, err
+	}
+	return model.MediaVariant{
+		MediaID: media.ID,
+		Name:    name,
+		Format:  ext,
+		File:    file,
+		URL:     "/uploads/" + file,
+		Width:   width,
+		Height:  height,
+		Size:    int64(len(data)),
+	}, nil
+}
+
+// encodeVariant encodes opaque images as JPEG and the rest as PNG.
+func encodeVariant(img *image.RGBA) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if utils.IsOpaque(img) {
+		err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: variantJPEGQuality})
+		return buf.Bytes(), utils.ImageJPEG, err
+	}
+	err := png.Encode(&buf, img)
+	return buf.Bytes(), utils.ImagePNG, err
+}
+
+// encodeWebP hands the image to cwebp as a lossless PNG.
+func encodeWebP(ctx context.Context, img *image.RGBA) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "webp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(in, buf.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, webpEncoder(), "-quiet", "-metadata", "none", "-q", fmt.Sprint(variantWebPQuality), in, "-o", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("cwebp: %w: %s", err, bytes.TrimSpace(output))
+	}
+	return os.ReadFile(out)
+}
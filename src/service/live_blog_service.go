@@ -0,0 +1,318 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/validation"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// liveBlogSnapshotSize is how many of the latest entries a new stream reader
+// is sent to start with.
+const liveBlogSnapshotSize = 20
+
+// LiveBlogTopic is the stream of one live blog. Events are "entry" with a new
+// entry, "update" with an edited one, "delete" with an entry id, "status"
+// with the blog when it starts or ends, and, sent by the stream endpoint,
+// "snapshot" with the blog and its latest entries.
+func LiveBlogTopic(id int) string {
+	return "liveblog:" + strconv.Itoa(id)
+}
+
+type LiveBlogService interface {
+	Create(req *validation.CreateLiveBlog, actor *model.User) (*model.LiveBlog, error)
+	Update(id int, req *validation.UpdateLiveBlog) (*model.LiveBlog, error)
+	GetPublic(id int) (*model.LiveBlog, error)
+	GetPublicByArticle(articleID int) (*model.LiveBlog, error)
+	GetEntries(id int, params *validation.QueryLiveBlogEntries) ([]model.LiveBlogEntry, int64, error)
+	Snapshot(id int) (*model.LiveBlogSnapshot, error)
+	AddEntry(id int, req *validation.CreateLiveBlogEntry, actor *model.User) (*model.LiveBlogEntry, error)
+	UpdateEntry(id, entryID int, req *validation.UpdateLiveBlogEntry) (*model.LiveBlogEntry, error)
+	DeleteEntry(id, entryID int) error
+}
+
+type liveBlogService struct {
+	db *gorm.DB
+}
+
+func NewLiveBlogService(db *gorm.DB) LiveBlogService {
+	return &liveBlogService{db: db}
+}
+
+type liveBlogEntryDeleted struct {
+	ID int `json:"id"`
+}
+
+// Create starts a live blog under an article. The article does not have to
+// be published yet, so coverage can be set up in advance.
+func (s *liveBlogService) Create(req *validation.CreateLiveBlog, actor *model.User) (*model.LiveBlog, error) {
+	var count int64
+	if err := s.db.Model(&model.Article{}).Where("id = ?", req.ArticleID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "article not found")
+	}
+
+	if err := s.db.Model(&model.LiveBlog{}).Where("article_id = ?", req.ArticleID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fiber.NewError(fiber.StatusConflict, "article already has a live blog")
+	}
+
+	blog := &model.LiveBlog{
+		ArticleID: req.ArticleID,
+		Title:     strings.TrimSpace(req.Title),
+		Status:    model.LiveBlogLive,
+	}
+	if actor != nil {
+		blog.CreatedBy = &actor.ID
+	}
+	if err := s.db.Create(blog).Error; err != nil {
+		return nil, err
+	}
+	return blog, nil
+}
+
+// Update renames a live blog or ends (and reopens) its coverage.
+func (s *liveBlogService) Update(id int, req *validation.UpdateLiveBlog) (*model.LiveBlog, error) {
+	var blog model.LiveBlog
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&blog, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "live blog not found")
+			}
+			return err
+		}
+
+		statusChanged := req.Status != nil && *req.Status != blog.Status
+		if req.Title != nil {
+			blog.Title = strings.TrimSpace(*req.Title)
+		}
+		if statusChanged {
+			blog.Status = *req.Status
+			blog.EndedAt = nil
+			if blog.Status == model.LiveBlogEnded {
+				now := time.Now()
+				blog.EndedAt = &now
+			}
+		}
+
+		if err := tx.Select("title", "status", "ended_at", "updated_at").Save(&blog).Error; err != nil {
+			return err
+		}
+		if statusChanged {
+			return publishEvent(tx, LiveBlogTopic(blog.ID), "status", &blog)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &blog, nil
+}
+
+// visible finds a live blog whose article readers can see.
+func (s *liveBlogService) visible(query *gorm.DB) (*model.LiveBlog, error) {
+	var blog model.LiveBlog
+	err := query.
+		Joins("JOIN articles ON articles.id = live_blogs.article_id").
+		Scopes(published).
+		Select("live_blogs.*").
+		First(&blog).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "live blog not found")
+		}
+		return nil, err
+	}
+
+	blog.Pinned = []model.LiveBlogEntry{}
+	err = s.db.Where("live_blog_id = ? AND pinned", blog.ID).
+		Order("created_at desc, id desc").
+		Find(&blog.Pinned).Error
+	if err != nil {
+		return nil, err
+	}
+	return &blog, nil
+}
+
+// GetPublic returns a live blog with its pinned entries.
+func (s *liveBlogService) GetPublic(id int) (*model.LiveBlog, error) {
+	return s.visible(s.db.Where("live_blogs.id = ?", id))
+}
+
+func (s *liveBlogService) GetPublicByArticle(articleID int) (*model.LiveBlog, error) {
+	return s.visible(s.db.Where("live_blogs.article_id = ?", articleID))
+}
+
+// GetEntries pages through a live blog, newest first. With Since only entries
+// posted after that moment are returned, for readers catching up.
+func (s *liveBlogService) GetEntries(id int, params *validation.QueryLiveBlogEntries) ([]model.LiveBlogEntry, int64, error) {
+	if _, err := s.GetPublic(id); err != nil {
+		return nil, 0, err
+	}
+
+	offset := paginate(&params.Page, &params.Limit, 20, 100)
+	query := s.db.Model(&model.LiveBlogEntry{}).Where("live_blog_id = ?", id)
+	if params.Since != nil {
+		query = query.Where("created_at > ?", *params.Since)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []model.LiveBlogEntry{}
+	err := query.Order("created_at desc, id desc").Offset(offset).Limit(params.Limit).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Snapshot is what a new stream reader starts from.
+func (s *liveBlogService) Snapshot(id int) (*model.LiveBlogSnapshot, error) {
+	blog, err := s.GetPublic(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _, err := s.GetEntries(id, &validation.QueryLiveBlogEntries{Page: 1, Limit: liveBlogSnapshotSize})
+	if err != nil {
+		return nil, err
+	}
+	return &model.LiveBlogSnapshot{LiveBlog: *blog, Entries: entries}, nil
+}
+
+// AddEntry posts an update. The byline defaults to the editor's name.
+func (s *liveBlogService) AddEntry(id int, req *validation.CreateLiveBlogEntry, actor *model.User) (*model.LiveBlogEntry, error) {
+	entry := &model.LiveBlogEntry{
+		LiveBlogID: id,
+		Body:       strings.TrimSpace(req.Body),
+		Author:     strings.TrimSpace(req.Author),
+		Pinned:     req.Pinned,
+	}
+	if entry.Body == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "body is required")
+	}
+	if err := setEntryImage(entry, req.Image); err != nil {
+		return nil, err
+	}
+	if actor != nil {
+		entry.AuthorID = &actor.ID
+		if entry.Author == "" {
+			entry.Author = actor.Name
+		}
+	}
+	if entry.Author == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "author is required")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var blog model.LiveBlog
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).First(&blog, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "live blog not found")
+			}
+			return err
+		}
+		if blog.Status != model.LiveBlogLive {
+			return fiber.NewError(fiber.StatusConflict, "live blog has ended")
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return publishEvent(tx, LiveBlogTopic(id), "entry", entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// UpdateEntry corrects an entry or pins and unpins it; entries of an ended
+// blog can still be corrected.
+func (s *liveBlogService) UpdateEntry(id, entryID int, req *validation.UpdateLiveBlogEntry) (*model.LiveBlogEntry, error) {
+	var entry model.LiveBlogEntry
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND live_blog_id = ?", entryID, id).
+			First(&entry).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "entry not found")
+			}
+			return err
+		}
+
+		if req.Body != nil {
+			entry.Body = strings.TrimSpace(*req.Body)
+			if entry.Body == "" {
+				return fiber.NewError(fiber.StatusBadRequest, "body is required")
+			}
+		}
+		if req.Author != nil {
+			entry.Author = strings.TrimSpace(*req.Author)
+			if entry.Author == "" {
+				return fiber.NewError(fiber.StatusBadRequest, "author is required")
+			}
+		}
+		if req.Image != nil {
+			if err := setEntryImage(&entry, req.Image); err != nil {
+				return err
+			}
+		}
+		if req.Pinned != nil {
+			entry.Pinned = *req.Pinned
+		}
+
+		if err := tx.Select("body", "image", "author", "pinned", "updated_at").Save(&entry).Error; err != nil {
+			return err
+		}
+		return publishEvent(tx, LiveBlogTopic(id), "update", &entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *liveBlogService) DeleteEntry(id, entryID int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND live_blog_id = ?", entryID, id).Delete(&model.LiveBlogEntry{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusNotFound, "entry not found")
+		}
+		return publishEvent(tx, LiveBlogTopic(id), "delete", liveBlogEntryDeleted{ID: entryID})
+	})
+}
+
+// setEntryImage sets or, given an empty string, clears an entry's image.
+func setEntryImage(entry *model.LiveBlogEntry, image *string) error {
+	if image == nil || strings.TrimSpace(*image) == "" {
+		entry.Image = nil
+		return nil
+	}
+
+	path := strings.TrimSpace(*image)
+	if !isSafeLink(path) {
+		return fiber.NewError(fiber.StatusBadRequest, "image must be a site path or an http(s) URL")
+	}
+	entry.Image = &path
+	return nil
+}
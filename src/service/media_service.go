@@ -0,0 +1,412 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/storage"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mediaReferences are the columns that can point at an upload, by its URL
+// or inside HTML.
+var mediaReferences = []struct{ table, column string }{
+	{"articles", "image"},
+	{"articles", "content"},
+	{"live_blog_entries", "image"},
+	{"live_blog_entries", "body"},
+	{"users", "avatar_url"},
+}
+
+// revisionReferences also keep uploads from the orphan sweep, so restoring an
+// old revision does not bring back a broken image. They do not stop a photo
+// being deleted from the library by hand; see unusedMedia.
+var revisionReferences = []struct{ table, column string }{
+	{"article_revisions", "image"},
+	{"article_revisions", "content"},
+}
+
+type MediaService interface {
+	List(params *validation.QueryMedia) ([]model.Media, int64, error)
+	Get(id int) (*model.Media, error)
+	Sizes(urls []string) (map[string]int64, error)
+	Update(id int, req *validation.MediaDetails) (*model.Media, error)
+	Usage(id int) (*model.MediaUsage, error)
+	Delete(id int) error
+	CleanOrphans(ctx context.Context) (int, error)
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type mediaService struct {
+	db    *gorm.DB
+	files storage.Storage
+}
+
+func NewMediaService(db *gorm.DB, files storage.Storage) MediaService {
+	return &mediaService{db: db, files: files}
+}
+
+// List searches the library, newest first. Search matches the alt text,
+// caption, credit, file name and uploader.
+func (s *mediaService) List(params *validation.QueryMedia) ([]model.Media, int64, error) {
+	offset := paginate(&params.Page, &params.Limit, 20, 100)
+
+	query := s.db.Model(&model.Media{})
+	if search := strings.TrimSpace(params.Search); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("alt ILIKE ? OR caption ILIKE ? OR credit ILIKE ? OR file ILIKE ? OR uploader_name ILIKE ?",
+			like, like, like, like, like)
+	}
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+	if credit := strings.TrimSpace(params.Credit); credit != "" {
+		query = query.Where("credit ILIKE ?", "%"+credit+"%")
+	}
+	if params.License != "" {
+		query = query.Where("license = ?", params.License)
+	}
+	if params.UploadedBy > 0 {
+		query = query.Where("uploaded_by = ?", params.UploadedBy)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Unused {
+		query = query.Where(unusedMedia())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.Media
+	err := query.Preload("Variants").Order("created_at desc, id desc").Offset(offset).Limit(params.Limit).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// unusedMedia matches media rows that nothing in mediaReferences points at.
+// Files are named by their hash, which is all a reference needs to contain.
+func unusedMedia() string {
+	clauses := make([]string, len(mediaReferences))
+	for i, ref := range mediaReferences {
+		clauses[i] = fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s LIKE '%%' || split_part(media.file, '.', 1) || '%%')",
+			ref.table, ref.column)
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+func (s *mediaService) Get(id int) (*model.Media, error) {
+	var media model.Media
+	if err := s.db.Preload("Variants").First(&media, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "media not found")
+		}
+		return nil, err
+	}
+	return &media, nil
+}
+
+// Sizes returns the size in bytes of each of urls that is a library file or
+// one of its variants; others are left out.
+func (s *mediaService) Sizes(urls []string) (map[string]int64, error) {
+	sizes := make(map[string]int64, len(urls))
+	if len(urls) == 0 {
+		return sizes, nil
+	}
+
+	type sized struct {
+		URL  string
+		Size int64
+	}
+	var files []sized
+	if err := s.db.Model(&model.Media{}).Select("url, size").Where("url IN ?", urls).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	var variants []sized
+	if err := s.db.Model(&model.MediaVariant{}).Select("url, size").Where("url IN ?", urls).Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	for _, f := range append(files, variants...) {
+		sizes[f.URL] = f.Size
+	}
+	return sizes, nil
+}
+
+// Update edits the description of a photo; fields left out are kept and
+// empty ones cleared.
+func (s *mediaService) Update(id int, req *validation.MediaDetails) (*model.Media, error) {
+	media, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if updates := mediaDetails(media, req, true); len(updates) > 0 {
+		if err := s.db.Model(media).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return media, nil
+}
+
+// mediaDetails copies details onto media and returns the changed columns.
+// Without overwrite only blank fields are filled in.
+func mediaDetails(media *model.Media, details *validation.MediaDetails, overwrite bool) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if details == nil {
+		return updates
+	}
+
+	set := func(column string, field, value *string) {
+		if value == nil {
+			return
+		}
+		v := strings.TrimSpace(*value)
+		if v == *field || !overwrite && (*field != "" || v == "") {
+			return
+		}
+		*field = v
+		updates[column] = v
+	}
+	set("alt", &media.Alt, details.Alt)
+	set("caption", &media.Caption, details.Caption)
+	set("credit", &media.Credit, details.Credit)
+	set("license", &media.License, details.License)
+	return updates
+}
+
+func (s *mediaService) Usage(id int) (*model.MediaUsage, error) {
+	media, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return mediaUsage(s.db, media)
+}
+
+// mediaUsage finds the articles, trashed ones included, live blog entries and
+// users that refer to media or to one of its variants.
+func mediaUsage(db *gorm.DB, media *model.Media) (*model.MediaUsage, error) {
+	like := "%" + strings.TrimSuffix(media.File, filepath.Ext(media.File)) + "%"
+	usage := &model.MediaUsage{
+		MediaID:         media.ID,
+		Articles:        []model.MediaArticle{},
+		LiveBlogEntries: []model.MediaLiveBlog{},
+		Users:           []model.MediaUser{},
+	}
+
+	err := db.Unscoped().Model(&model.Article{}).
+		Select("id, title, COALESCE(slug, '') AS slug, status, deleted_at IS NOT NULL AS trashed, "+
+			"COALESCE(image LIKE ?, false) AS in_image, content LIKE ? AS in_content", like, like).
+		Where("image LIKE ? OR content LIKE ?", like, like).
+		Order("id desc").
+		Scan(&usage.Articles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Table("live_blog_entries").
+		Select("live_blog_entries.id, live_blog_entries.live_blog_id, live_blogs.article_id").
+		Joins("JOIN live_blogs ON live_blogs.id = live_blog_entries.live_blog_id").
+		Where("live_blog_entries.image LIKE ? OR live_blog_entries.body LIKE ?", like, like).
+		Order("live_blog_entries.id desc").
+		Scan(&usage.LiveBlogEntries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model(&model.User{}).Select("id, name").Where("avatar_url LIKE ?", like).Order("id").Scan(&usage.Users).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// Delete removes a photo nothing uses, with its variants. Files that cannot
+// be removed are left for CleanOrphans.
+func (s *mediaService) Delete(id int) error {
+	var media model.Media
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&media, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "media not found")
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("media_id = ?", media.ID).Find(&media.Variants).Error; err != nil {
+			return err
+		}
+		if media.Status == model.MediaProcessing {
+			return fiber.NewError(fiber.StatusConflict, "the image is still being processed; try again shortly")
+		}
+
+		usage, err := mediaUsage(tx, &media)
+		if err != nil {
+			return err
+		}
+		if usage.Count() > 0 {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf(
+				"the image is still in use (articles: %d, live blog entries: %d, avatars: %d)",
+				len(usage.Articles), len(usage.LiveBlogEntries), len(usage.Users)))
+		}
+
+		if err := tx.Where("media_id = ?", media.ID).Delete(&model.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&media).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	files := []string{media.File}
+	for _, v := range media.Variants {
+		files = append(files, v.File)
+	}
+	for _, file := range files {
+		if err := s.files.Delete(context.Background(), file); err != nil {
+			utils.Log.Errorf("Media: could not remove %s: %+v", file, err)
+		}
+	}
+	return nil
+}
+
+// Run sweeps orphaned uploads now and then every interval, until ctx is
+// cancelled.
+func (s *mediaService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := s.CleanOrphans(ctx)
+		if err != nil {
+			utils.Log.Errorf("Media: orphan cleanup failed: %+v", err)
+		} else if removed > 0 {
+			utils.Log.Infof("Media: removed %d orphaned upload(s)", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanOrphans removes uploaded files, older than
+// config.UploadOrphanGrace, that are neither in the media library nor
+// referred to by anything in mediaReferences, by name or URL-escaped name.
+// Files uploaded before the library existed are kept as long as something
+// refers to them. It returns how many files it removed.
+func (s *mediaService) CleanOrphans(ctx context.Context) (int, error) {
+	if config.UploadOrphanGrace <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-config.UploadOrphanGrace)
+	candidates := map[string]bool{}
+	err := s.files.List(ctx, func(info *storage.Info) error {
+		// Hidden files such as .gitkeep are not uploads; those left by an
+		// interrupted upload are
+		if strings.HasPrefix(info.Key, ".") && !strings.HasPrefix(info.Key, storage.TempPrefix) {
+			return nil
+		}
+		if info.ModTime.Before(cutoff) {
+			candidates[info.Key] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ref := range append(mediaReferences, revisionReferences...) {
+		if len(candidates) == 0 {
+			return 0, nil
+		}
+		if err := s.dropReferenced(ctx, candidates, ref.table, ref.column); err != nil {
+			return 0, err
+		}
+	}
+
+	// Checked last, so an upload that has just adopted an old file is seen
+	if err := s.dropKnown(ctx, candidates); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for name := range candidates {
+		if err := s.files.Delete(ctx, name); err != nil {
+			utils.Log.Errorf("Media: could not remove orphaned upload %s: %+v", name, err)
+			continue
+		}
+		utils.Log.Infof("Media: removed orphaned upload %s", name)
+		removed++
+	}
+	return removed, nil
+}
+
+// dropReferenced streams column of table and takes every file it mentions
+// out of candidates.
+func (s *mediaService) dropReferenced(ctx context.Context, candidates map[string]bool, table, column string) error {
+	rows, err := s.db.WithContext(ctx).Table(table).Select(column).Where(column+" LIKE ?", "%uploads/%").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	escaped := make(map[string]string, len(candidates))
+	for name := range candidates {
+		escaped[name] = url.PathEscape(name)
+	}
+
+	for rows.Next() && len(candidates) > 0 {
+		var text sql.NullString
+		if err := rows.Scan(&text); err != nil {
+			return err
+		}
+		for name := range candidates {
+			if strings.Contains(text.String, name) || strings.Contains(text.String, escaped[name]) {
+				delete(candidates, name)
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// dropKnown takes the files of the media library out of candidates.
+func (s *mediaService) dropKnown(ctx context.Context, candidates map[string]bool) error {
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+
+	for start := 0; start < len(names); start += 1000 {
+		batch := names[start:min(start+1000, len(names))]
+		var known []string
+		err := s.db.WithContext(ctx).Raw("SELECT file FROM media WHERE file IN ? UNION SELECT file FROM media_variants WHERE file IN ?",
+			batch, batch).Scan(&known).Error
+		if err != nil {
+			return err
+		}
+		for _, name := range known {
+			delete(candidates, name)
+		}
+	}
+	return nil
+}
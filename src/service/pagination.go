@@ -0,0 +1,13 @@
+package service
+
+// paginate applies the usual defaults to page and limit in place, so callers
+// can echo the effective values back, and returns the matching offset.
+func paginate(page, limit *int, defaultLimit, maxLimit int) int {
+	if *page <= 0 {
+		*page = 1
+	}
+	if *limit <= 0 || *limit > maxLimit {
+		*limit = defaultLimit
+	}
+	return (*page - 1) * *limit
+}
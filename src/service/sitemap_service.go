@@ -0,0 +1,137 @@
+package service
+
+import (
+	"app/src/model"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sitemap limits from the sitemaps.org protocol and Google News.
+const (
+	SitemapPageSize = 50000
+	NewsSitemapSize = 1000
+	NewsSitemapAge  = 48 * time.Hour
+)
+
+// SitemapPage describes one article sitemap file. Its fingerprint changes
+// whenever an article in it is added, removed or updated, so only pages
+// that changed need to be rendered again.
+type SitemapPage struct {
+	Number  int
+	Count   int64
+	FirstID int
+	LastID  int
+	LastMod time.Time
+}
+
+func (p SitemapPage) Fingerprint() string {
+	return fmt.Sprintf("%d:%d:%d:%d", p.Count, p.FirstID, p.LastID, p.LastMod.UnixMicro())
+}
+
+// SitemapCategory is a public category with the time its newest article
+// last changed.
+type SitemapCategory struct {
+	model.Category
+	LastMod *time.Time
+}
+
+type SitemapService interface {
+	ArticlePages() ([]SitemapPage, error)
+	PageArticles(page SitemapPage) ([]model.Article, error)
+	NewsArticles() ([]model.Article, error)
+	Categories() ([]SitemapCategory, error)
+}
+
+type sitemapService struct {
+	db *gorm.DB
+
+	mu      sync.Mutex
+	version int64
+	pages   []SitemapPage
+}
+
+func NewSitemapService(db *gorm.DB) SitemapService {
+	return &sitemapService{db: db, version: -1}
+}
+
+// ArticlePages splits published articles, in ID order, into pages of
+// SitemapPageSize. The split is recomputed only after an article changes.
+func (s *sitemapService) ArticlePages() ([]SitemapPage, error) {
+	version := ArticleVersion()
+	s.mu.Lock()
+	if s.version == version {
+		pages := s.pages
+		s.mu.Unlock()
+		return pages, nil
+	}
+	s.mu.Unlock()
+
+	var pages []SitemapPage
+	err := s.db.Table("(?) AS numbered",
+		s.db.Model(&model.Article{}).
+			Scopes(published).
+			Select("articles.id, articles.updated_at, (row_number() OVER (ORDER BY articles.id) - 1) / ? AS page", SitemapPageSize)).
+		Select("page + 1 AS number, count(*) AS count, min(id) AS first_id, max(id) AS last_id, max(updated_at) AS last_mod").
+		Group("page").
+		Order("page").
+		Scan(&pages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.version = version
+	s.pages = pages
+	s.mu.Unlock()
+	return pages, nil
+}
+
+func (s *sitemapService) PageArticles(page SitemapPage) ([]model.Article, error) {
+	var items []model.Article
+	err := s.db.Scopes(published).
+		Select("articles.id", "articles.slug", "articles.title", "articles.image", "articles.updated_at").
+		Where("articles.id BETWEEN ? AND ?", page.FirstID, page.LastID).
+		Order("articles.id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// NewsArticles are the articles published in the last NewsSitemapAge,
+// newest first, for the Google News sitemap.
+func (s *sitemapService) NewsArticles() ([]model.Article, error) {
+	var items []model.Article
+	err := s.db.Scopes(published).
+		Select("articles.id", "articles.slug", "articles.title", "articles.created_at", "articles.publish_at", "articles.updated_at").
+		Where("COALESCE(articles.publish_at, articles.created_at) >= ?", time.Now().Add(-NewsSitemapAge)).
+		Order("COALESCE(articles.publish_at, articles.created_at) desc").
+		Limit(NewsSitemapSize).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *sitemapService) Categories() ([]SitemapCategory, error) {
+	var items []SitemapCategory
+	err := s.db.Model(&model.Category{}).
+		Select("categories.*, latest.last_mod").
+		Joins(`LEFT JOIN (?) AS latest ON latest.category_id = categories.id`,
+			s.db.Model(&model.Article{}).
+				Scopes(published).
+				Select("articles.category_id, max(articles.updated_at) AS last_mod").
+				Group("articles.category_id")).
+		Where("categories.is_active = ?", true).
+		Order("categories.sort_order asc, categories.id asc").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
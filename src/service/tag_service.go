@@ -0,0 +1,258 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagService interface {
+	GetAll(params *validation.QueryTag) ([]model.TagCount, int64, error)
+	Suggest(q string, limit int) ([]model.Tag, error)
+	GetArticles(slug string, params *validation.QueryTag) (*model.Tag, []model.Article, int64, error)
+	Rename(id int, req *validation.RenameTag) (*model.Tag, error)
+	Merge(id int, req *validation.MergeTag) (*model.Tag, error)
+}
+
+type tagService struct {
+	db *gorm.DB
+}
+
+func NewTagService(db *gorm.DB) TagService {
+	return &tagService{db: db}
+}
+
+// tagCounts selects tags with the number of published articles using them.
+func (s *tagService) tagCounts() *gorm.DB {
+	return s.db.Model(&model.Tag{}).
+		Select("tags.*, COUNT(articles.id) AS article_count").
+		Joins("LEFT JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins(`LEFT JOIN articles ON articles.id = article_tags.article_id
+			AND articles.deleted_at IS NULL
+			AND articles.status = ?
+			AND (articles.publish_at IS NULL OR articles.publish_at <= now())
+			AND (articles.expire_at IS NULL OR articles.expire_at > now())`, model.ArticleStatusPublished).
+		Group("tags.id")
+}
+
+func (s *tagService) GetAll(params *validation.QueryTag) ([]model.TagCount, int64, error) {
+	offset := paginate(&params.Page, &params.Limit, 50, 100)
+
+	query := s.db.Model(&model.Tag{})
+	if params.Search != "" {
+		query = query.Where("name ILIKE ? OR slug ILIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.TagCount
+	counts := s.tagCounts()
+	if params.Search != "" {
+		counts = counts.Where("tags.name ILIKE ? OR tags.slug ILIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
+	err := counts.
+		Order("article_count desc, tags.name asc").
+		Limit(params.Limit).
+		Offset(offset).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// Suggest returns tags starting with q, most used first, for auto-complete.
+func (s *tagService) Suggest(q string, limit int) ([]model.Tag, error) {
+	q = strings.TrimSpace(utils.NormalizeText(q))
+	if q == "" {
+		return []model.Tag{}, nil
+	}
+	if limit <= 0 || limit > 20 {
+		limit = 10
+	}
+
+	var items []model.TagCount
+	err := s.tagCounts().
+		Where("tags.name ILIKE ? OR tags.slug LIKE ?", q+"%", utils.Slugify(q)+"%").
+		Order("article_count desc, tags.name asc").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]model.Tag, len(items))
+	for i, item := range items {
+		tags[i] = item.Tag
+	}
+	return tags, nil
+}
+
+func (s *tagService) getBySlug(slug string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := s.db.Where("slug = ?", utils.Slugify(slug)).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "tag not found")
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (s *tagService) getByID(id int) (*model.Tag, error) {
+	var tag model.Tag
+	if err := s.db.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "tag not found")
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetArticles returns one page of the topic page for a tag.
+func (s *tagService) GetArticles(slug string, params *validation.QueryTag) (*model.Tag, []model.Article, int64, error) {
+	tag, err := s.getBySlug(slug)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	offset := paginate(&params.Page, &params.Limit, 20, 50)
+
+	query := s.db.Model(&model.Article{}).
+		Scopes(published).
+		Where("articles.id IN (?)", s.db.Table("article_tags").Select("article_id").Where("tag_id = ?", tag.ID)).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, 0, err
+	}
+
+	var items []model.Article
+	err = query.
+		Preload("Tags").
+		Order("updated_at desc").
+		Limit(params.Limit).
+		Offset(offset).
+		Find(&items).Error
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return tag, items, total, nil
+}
+
+func (s *tagService) Rename(id int, req *validation.RenameTag) (*model.Tag, error) {
+	tag, err := s.getByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	slug := utils.Slugify(firstNonEmpty(req.Slug, req.Name))
+	if slug == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "tag name must contain letters or digits")
+	}
+
+	err = s.db.Model(tag).Updates(map[string]interface{}{
+		"name": utils.NormalizeText(strings.TrimSpace(req.Name)),
+		"slug": slug,
+	}).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fiber.NewError(fiber.StatusConflict, "another tag already uses this slug; merge the tags instead")
+		}
+		return nil, err
+	}
+
+	touchArticles()
+	return s.getByID(id)
+}
+
+// Merge moves every article from tag id onto req.Into and deletes tag id.
+func (s *tagService) Merge(id int, req *validation.MergeTag) (*model.Tag, error) {
+	if id == req.Into {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "cannot merge a tag into itself")
+	}
+	if _, err := s.getByID(id); err != nil {
+		return nil, err
+	}
+	target, err := s.getByID(req.Into)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO article_tags (article_id, tag_id)
+			SELECT article_id, ? FROM article_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, id).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM article_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	touchArticles()
+	return target, nil
+}
+
+// resolveTags maps the tags sent with an article to stored tags, creating
+// the missing ones. Tags are matched by ID when given, otherwise by slug, so
+// "বাজেট ২০২৬" and "বাজেট  ২০২৬" end up as the same tag.
+func resolveTags(tx *gorm.DB, tags []model.Tag) ([]model.Tag, error) {
+	resolved := make([]model.Tag, 0, len(tags))
+	seen := map[int]bool{}
+
+	for _, t := range tags {
+		var tag model.Tag
+		if t.ID > 0 {
+			if err := tx.First(&tag, t.ID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, fiber.NewError(fiber.StatusBadRequest, "unknown tag id")
+				}
+				return nil, err
+			}
+		} else {
+			name := utils.NormalizeText(strings.Join(strings.Fields(t.Name), " "))
+			slug := utils.Slugify(name)
+			if slug == "" {
+				continue
+			}
+
+			tag = model.Tag{Slug: slug, Name: name}
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error
+			if err != nil {
+				return nil, err
+			}
+			if tag.ID == 0 {
+				if err := tx.Where("slug = ?", slug).First(&tag).Error; err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			resolved = append(resolved, tag)
+		}
+	}
+
+	return resolved, nil
+}
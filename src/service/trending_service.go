@@ -0,0 +1,243 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/utils"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type TrendingService interface {
+	GetTrending(category string, limit int) ([]model.TrendingArticle, error)
+	RecordShare(articleID int, network, ip, userAgent string) error
+	Recompute(ctx context.Context) error
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type trendingService struct {
+	db *gorm.DB
+}
+
+func NewTrendingService(db *gorm.DB) TrendingService {
+	return &trendingService{db: db}
+}
+
+type trendingScore struct {
+	ArticleID  int
+	CategoryID *int
+	Score      float64
+}
+
+// GetTrending returns the latest computed list for a category, site-wide when
+// category is empty. Articles unpublished since the last run are skipped.
+func (s *trendingService) GetTrending(category string, limit int) ([]model.TrendingArticle, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	categoryID := 0
+	if category != "" && category != "সব" {
+		cat, err := findCategory(s.db, category)
+		if err != nil {
+			return nil, err
+		}
+		categoryID = cat.ID
+	}
+
+	var items []model.TrendingArticle
+	err := s.db.Model(&model.Article{}).
+		Scopes(published).
+		Joins("JOIN trending_entries ON trending_entries.article_id = articles.id AND trending_entries.category_id = ?", categoryID).
+		Select("articles.*, trending_entries.score").
+		Order("trending_entries.rank").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]*model.Article, len(items))
+	for i := range items {
+		refs[i] = &items[i].Article
+	}
+	attachImages(s.db, refs...)
+	return items, nil
+}
+
+// RecordShare counts a reader sharing an article. Shares are rare next to
+// views, so they are written straight away; like views, a visitor (IP and
+// User-Agent) is counted once per article per de-duplication window.
+func (s *trendingService) RecordShare(articleID int, network, ip, userAgent string) error {
+	if utils.IsBot(userAgent) {
+		return nil
+	}
+
+	var count int64
+	if err := s.db.Model(&model.Article{}).Scopes(published).Where("articles.id = ?", articleID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "Article not found")
+	}
+
+	if network == "" {
+		network = "other"
+	}
+	now := time.Now()
+	return s.db.Exec(`
+		WITH fresh AS (
+			INSERT INTO article_sharers (article_id, visitor, bucket, shared_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+			RETURNING article_id, shared_at
+		)
+		INSERT INTO article_share_stats (article_id, hour, network, shares)
+		SELECT article_id, date_trunc('hour', shared_at), ?, 1 FROM fresh
+		ON CONFLICT (article_id, hour, network) DO UPDATE SET shares = article_share_stats.shares + 1`,
+		articleID, visitorID(ip, userAgent), now.UnixNano()/int64(shareWindow()), now,
+		strings.ToLower(network)).Error
+}
+
+// shareWindow is the de-duplication window of shares, the same as of views.
+func shareWindow() time.Duration {
+	if config.ViewDedupeWindow <= 0 {
+		return 30 * time.Minute
+	}
+	return config.ViewDedupeWindow
+}
+
+// Recompute scores every published article with activity in the trending
+// window and replaces the stored lists. Each view, approved or pending
+// comment and share adds its configured weight, decayed by the age of the
+// hour it happened in, so a story that is picking up readers now beats one
+// that had more readers yesterday.
+func (s *trendingService) Recompute(ctx context.Context) error {
+	since := time.Now().Add(-config.TrendingWindow)
+	halfLifeHours := config.TrendingHalfLife.Hours()
+	if halfLifeHours <= 0 {
+		return errors.New("trending half-life must be positive")
+	}
+
+	var scores []trendingScore
+	err := s.db.WithContext(ctx).
+		Table("articles").
+		Scopes(published).
+		Joins(`JOIN (
+			SELECT article_id, hour AS at, views * ? AS weight
+			FROM article_view_stats WHERE hour >= ?
+			UNION ALL
+			SELECT article_id, hour, shares * ?
+			FROM article_share_stats WHERE hour >= ?
+			UNION ALL
+			SELECT articles.id, com.createdtime, ?
+			FROM com JOIN articles ON com.newsidentity IN (articles.id::text, articles.slug)
+			WHERE com.createdtime >= ? AND com.approvedstatus <> 2
+		) AS events ON events.article_id = articles.id`,
+			config.TrendingViewWeight, since,
+			config.TrendingShareWeight, since,
+			config.TrendingComWeight, since).
+		Select(`articles.id AS article_id, articles.category_id,
+			sum(events.weight * exp(-ln(2) * extract(epoch FROM now() - events.at) / 3600 / ?)) AS score`,
+			halfLifeHours).
+		Group("articles.id").
+		Order("score desc, articles.id desc").
+		Scan(&scores).Error
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.buildLists(ctx, scores)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.TrendingEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(entries, 500).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sharers whose window has passed are no longer needed to dedupe
+	return s.db.WithContext(ctx).
+		Where("shared_at < ?", time.Now().Add(-2*shareWindow())).
+		Delete(&model.ArticleSharer{}).Error
+}
+
+// buildLists turns scores, best first, into the site-wide list and one list
+// per category. An article counts towards its category and every ancestor.
+func (s *trendingService) buildLists(ctx context.Context, scores []trendingScore) ([]model.TrendingEntry, error) {
+	var categories []model.Category
+	if err := s.db.WithContext(ctx).Select("id", "parent_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	parents := make(map[int]*int, len(categories))
+	for _, cat := range categories {
+		parents[cat.ID] = cat.ParentID
+	}
+
+	size := config.TrendingSize
+	if size <= 0 {
+		size = 20
+	}
+	now := time.Now()
+	lengths := map[int]int{}
+	var entries []model.TrendingEntry
+
+	add := func(categoryID int, score trendingScore) {
+		if lengths[categoryID] >= size {
+			return
+		}
+		lengths[categoryID]++
+		entries = append(entries, model.TrendingEntry{
+			CategoryID: categoryID,
+			Rank:       lengths[categoryID],
+			ArticleID:  score.ArticleID,
+			Score:      score.Score,
+			ComputedAt: now,
+		})
+	}
+
+	for _, score := range scores {
+		add(0, score)
+		// Guard against a parent cycle in hand-edited data
+		seen := map[int]bool{}
+		for id := score.CategoryID; id != nil && !seen[*id]; id = parents[*id] {
+			seen[*id] = true
+			add(*id, score)
+		}
+	}
+
+	return entries, nil
+}
+
+// Run recomputes the lists every interval until ctx is cancelled. Only one
+// process needs to run it; every process serves the stored lists.
+func (s *trendingService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Recompute(ctx); err != nil {
+			utils.Log.Errorf("Failed to recompute trending articles: %+v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,719 @@
+package service
+
+import (
+	"app/src/config"
+	"app/src/model"
+	"app/src/storage"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Parts of resumable uploads are hidden files, so the orphan sweep
+	// leaves them to the upload expiry
+	uploadPartPrefix = ".tus-"
+
+	// An upload whose file has been processing this long, by a process that
+	// has since died, may be finished again. Joining the parts of a large
+	// video can take minutes.
+	staleAssembly = time.Hour
+
+	// Chunks one upload may be sent in, which keeps tiny chunks from
+	// littering the storage with parts
+	maxUploadParts = 10000
+
+	// Expired uploads removed per query
+	uploadExpiryBatch = 100
+
+	// Bytes read from the start of a finished upload to tell its format
+	uploadSniffBytes = 64
+)
+
+type UploadService interface {
+	Create(length int64, metadata string, actor *model.User) (*model.ResumableUpload, error)
+	Get(id string, actor *model.User) (*model.ResumableUpload, error)
+	Write(id string, offset int64, chunk []byte, actor *model.User) (*model.ResumableUpload, error)
+	Terminate(id string, actor *model.User) error
+	Attach(id string, req *validation.AttachUpload, actor *model.User) (*model.Article, error)
+	ExpireAbandoned(ctx context.Context) (int, error)
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type uploadService struct {
+	db     *gorm.DB
+	files  storage.Storage
+	images ImageService
+}
+
+func NewUploadService(db *gorm.DB, files storage.Storage, images ImageService) UploadService {
+	return &uploadService{db: db, files: files, images: images}
+}
+
+// Create starts an upload of length bytes for actor. metadata is the
+// Upload-Metadata header; filename (or name), alt, caption, credit and
+// license are read from it. The declared file type is not trusted: the
+// finished file is checked by its content. Each user may have at most
+// config.TusQuotaBytes of uploads unfinished.
+func (s *uploadService) Create(length int64, metadata string, actor *model.User) (*model.ResumableUpload, error) {
+	if actor == nil {
+		return nil, fiber.ErrUnauthorized
+	}
+	if length <= 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Upload-Length must be a positive number of bytes")
+	}
+	if length > config.TusMaxBytes {
+		return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf(
+			"the file is larger than %.0f MB", float64(config.TusMaxBytes)/(1<<20)))
+	}
+
+	meta, err := parseUploadMetadata(metadata)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Upload-Metadata is malformed: "+err.Error())
+	}
+	if err := validation.Validator().Struct(uploadDetails(meta)); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	filename := meta["filename"]
+	if filename == "" {
+		filename = meta["name"]
+	}
+	if runes := []rune(filename); len(runes) > 255 {
+		filename = string(runes[:255])
+	}
+
+	id, err := uploadID()
+	if err != nil {
+		return nil, err
+	}
+	upload := &model.ResumableUpload{
+		ID:        id,
+		UserID:    actor.ID,
+		Length:    length,
+		Metadata:  metadata,
+		Filename:  filename,
+		Status:    model.UploadReceiving,
+		ExpiresAt: time.Now().Add(config.TusExpiry),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// One user's uploads are created one at a time, so two cannot both
+		// fit in what is left of the quota
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.User{}, actor.ID).Error
+		if err != nil {
+			return err
+		}
+
+		if config.TusQuotaBytes > 0 {
+			var unfinished int64
+			err := tx.Model(&model.ResumableUpload{}).
+				Where("user_id = ? AND status IN ? AND expires_at > ?",
+					actor.ID, []string{model.UploadReceiving, model.UploadProcessing}, time.Now()).
+				Select("COALESCE(SUM(length), 0)").
+				Scan(&unfinished).Error
+			if err != nil {
+				return err
+			}
+			if unfinished+length > config.TusQuotaBytes {
+				return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf(
+					"your unfinished uploads would come to %.0f MB; the limit is %.0f MB. Finish or cancel one first",
+					float64(unfinished+length)/(1<<20), float64(config.TusQuotaBytes)/(1<<20)))
+			}
+		}
+		return tx.Create(upload).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get reports an upload of actor's. An expired one is gone.
+func (s *uploadService) Get(id string, actor *model.User) (*model.ResumableUpload, error) {
+	var upload model.ResumableUpload
+	if err := findUpload(s.db.Preload("Media.Variants"), &upload, id, actor); err != nil {
+		return nil, err
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, uploadGone()
+	}
+	return &upload, nil
+}
+
+// Write stores chunk, which must start at the upload's offset, as the next
+// part of the upload and moves its expiry on. With the last chunk the file
+// is finished: see finish.
+func (s *uploadService) Write(id string, offset int64, chunk []byte, actor *model.User) (*model.ResumableUpload, error) {
+	var upload model.ResumableUpload
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findUpload(tx.Clauses(clause.Locking{Strength: "UPDATE"}), &upload, id, actor); err != nil {
+			return err
+		}
+		if time.Now().After(upload.ExpiresAt) {
+			return uploadGone()
+		}
+		if err := checkReceiving(&upload); err != nil {
+			return err
+		}
+		if offset != upload.Offset {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf(
+				"Upload-Offset is %d, but %d bytes have been received", offset, upload.Offset))
+		}
+		if offset+int64(len(chunk)) > upload.Length {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf(
+				"the chunk goes past the Upload-Length of %d bytes", upload.Length))
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+		if upload.Parts >= maxUploadParts {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf(
+				"an upload can be sent in at most %d chunks; send larger ones", maxUploadParts))
+		}
+
+		sum, err := uploadHash(&upload)
+		if err != nil {
+			return err
+		}
+		sum.Write(chunk)
+		state, err := sum.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		if err := s.files.Put(context.Background(), uploadPart(upload.ID, upload.Parts), chunk, "application/octet-stream"); err != nil {
+			return err
+		}
+		upload.Offset += int64(len(chunk))
+		upload.Parts++
+		upload.HashState = state
+		upload.ExpiresAt = time.Now().Add(config.TusExpiry)
+		return tx.Model(&upload).Updates(map[string]interface{}{
+			"offset":     upload.Offset,
+			"parts":      upload.Parts,
+			"hash_state": upload.HashState,
+			"expires_at": upload.ExpiresAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if upload.Offset == upload.Length {
+		return s.finish(&upload, actor)
+	}
+	return &upload, nil
+}
+
+// finish checks the fully received file by its content and adds it to the
+// media library: a photo as Upload would, a video as it is. A file that is
+// refused fails the upload, and why is returned. Either way the parts are
+// removed. When something else went wrong the upload is left to be finished
+// again, by an empty chunk at its full length or by finishStalled.
+func (s *uploadService) finish(upload *model.ResumableUpload, actor *model.User) (*model.ResumableUpload, error) {
+	claim := s.db.Model(upload).
+		Where("status = ? OR (status = ? AND updated_at < ?)",
+			model.UploadReceiving, model.UploadProcessing, time.Now().Add(-staleAssembly)).
+		Update("status", model.UploadProcessing)
+	if claim.Error != nil {
+		return nil, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		// Being finished by another request
+		upload.Status = model.UploadProcessing
+		return upload, nil
+	}
+
+	ctx := context.Background()
+	media, err := s.store(ctx, upload, actor)
+	var refused *fiber.Error
+	if err != nil && !errors.As(err, &refused) {
+		if resetErr := s.db.Model(upload).Update("status", model.UploadReceiving).Error; resetErr != nil {
+			utils.Log.Errorf("Uploads: could not reset %s: %+v", upload.ID, resetErr)
+		}
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"expires_at": time.Now().Add(config.TusExpiry)}
+	if refused != nil {
+		updates["status"], updates["error"] = model.UploadFailed, refused.Message
+	} else {
+		updates["status"], updates["media_id"] = model.UploadComplete, media.ID
+	}
+	if err := s.db.Model(upload).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := s.removeParts(ctx, upload); err != nil {
+		// Tried again when the upload expires
+		utils.Log.Errorf("Uploads: could not remove the parts of %s: %+v", upload.ID, err)
+	}
+
+	if refused != nil {
+		return nil, refused
+	}
+	upload.Media, upload.MediaID = media, &media.ID
+	return upload, nil
+}
+
+// store joins the parts of upload into a media library file.
+func (s *uploadService) store(ctx context.Context, upload *model.ResumableUpload, actor *model.User) (*model.Media, error) {
+	meta, _ := parseUploadMetadata(upload.Metadata)
+	details := uploadDetails(meta)
+
+	parts := s.parts(ctx, upload)
+	head, err := io.ReadAll(io.LimitReader(parts, uploadSniffBytes))
+	parts.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if utils.SniffImage(head) != "" {
+		if upload.Length > config.UploadMaxBytes {
+			return nil, tooLarge()
+		}
+		parts := s.parts(ctx, upload)
+		defer parts.Close()
+		data, err := io.ReadAll(parts)
+		if err != nil {
+			return nil, err
+		}
+		image, err := s.images.Store(data, details, actor)
+		if err != nil {
+			return nil, err
+		}
+		var media model.Media
+		if err := s.db.Preload("Variants").First(&media, image.Images.ID).Error; err != nil {
+			return nil, err
+		}
+		return &media, nil
+	}
+
+	format := utils.SniffVideo(head)
+	if format == "" {
+		return nil, fiber.NewError(fiber.StatusUnsupportedMediaType,
+			"only JPEG, PNG, WebP, GIF and AVIF images and MP4, QuickTime and WebM videos can be uploaded")
+	}
+
+	sum, err := uploadHash(upload)
+	if err != nil {
+		return nil, err
+	}
+	name := hex.EncodeToString(sum.Sum(nil)) + "." + format
+	parts = s.parts(ctx, upload)
+	defer parts.Close()
+	if _, err := storeUpload(ctx, s.files, name, parts, upload.Length, utils.VideoMIME[format]); err != nil {
+		return nil, err
+	}
+
+	media := &model.Media{
+		File:   name,
+		URL:    "/uploads/" + name,
+		Type:   utils.VideoMIME[format],
+		Size:   upload.Length,
+		Status: model.MediaSkipped,
+		Error:  errNoVariants.Error(),
+	}
+	if actor != nil {
+		media.UploadedBy, media.UploaderName = &actor.ID, actor.Name
+	}
+	return recordMedia(s.db, media, details)
+}
+
+// Terminate cancels an upload and removes what was received. For a finished
+// one only the upload is forgotten; its file stays in the media library.
+func (s *uploadService) Terminate(id string, actor *model.User) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var upload model.ResumableUpload
+		if err := findUpload(tx.Clauses(clause.Locking{Strength: "UPDATE"}), &upload, id, actor); err != nil {
+			return err
+		}
+		if upload.Status == model.UploadProcessing && time.Since(upload.UpdatedAt) < staleAssembly {
+			return fiber.NewError(fiber.StatusConflict, "the upload is being processed")
+		}
+		if err := s.removeParts(context.Background(), &upload); err != nil {
+			return err
+		}
+		return tx.Delete(&upload).Error
+	})
+}
+
+// Attach puts the file of a finished upload on an article, as a new
+// revision: a photo as its main image or, like a video, at the end of its
+// body.
+func (s *uploadService) Attach(id string, req *validation.AttachUpload, actor *model.User) (*model.Article, error) {
+	upload, err := s.Get(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != model.UploadComplete {
+		return nil, fiber.NewError(fiber.StatusConflict, "the upload is not complete")
+	}
+	media := upload.Media
+	if media == nil {
+		return nil, fiber.NewError(fiber.StatusConflict, "the uploaded file has been deleted from the media library")
+	}
+
+	video := strings.HasPrefix(media.Type, "video/")
+	placement := req.Placement
+	if placement == "" {
+		placement = "image"
+		if video {
+			placement = "content"
+		}
+	}
+	if video && placement == "image" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "a video can only be placed in the article body")
+	}
+
+	var article model.Article
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&article, req.ArticleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "article not found")
+			}
+			return err
+		}
+		if err := checkEditable(&article, actor); err != nil {
+			return err
+		}
+		if req.Version != 0 && req.Version != article.Version {
+			return &StaleArticleError{Current: &article}
+		}
+
+		updates := map[string]interface{}{"version": article.Version + 1, "updated_at": time.Now()}
+		if placement == "image" {
+			updates["image"] = media.URL
+		} else {
+			updates["content"] = strings.TrimRight(article.Content, "\n") + "\n" + mediaFigure(media)
+		}
+		if err := tx.Model(&article).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Preload("Tags").First(&article, article.ID).Error; err != nil {
+			return err
+		}
+		return saveRevision(tx, &article, actor, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	touchArticles()
+	attachImages(s.db, &article)
+	return &article, nil
+}
+
+// mediaFigure is the markup for a photo or video placed in an article body.
+func mediaFigure(media *model.Media) string {
+	var b strings.Builder
+	if strings.HasPrefix(media.Type, "video/") {
+		b.WriteString(`<figure class="article-video"><video controls preload="metadata" src="` +
+			html.EscapeString(media.URL) + `"></video>`)
+	} else {
+		b.WriteString(`<figure class="article-figure"><img src="` + html.EscapeString(media.URL) +
+			`" alt="` + html.EscapeString(media.Alt) + `"`)
+		if media.Width > 0 && media.Height > 0 {
+			b.WriteString(` width="` + strconv.Itoa(media.Width) + `" height="` + strconv.Itoa(media.Height) + `"`)
+		}
+		b.WriteString(` loading="lazy">`)
+	}
+	if media.Caption != "" || media.Credit != "" {
+		b.WriteString("<figcaption>" + html.EscapeString(media.Caption))
+		if media.Credit != "" {
+			b.WriteString(` <span class="image-credit">` + html.EscapeString(media.Credit) + `</span>`)
+		}
+		b.WriteString("</figcaption>")
+	}
+	b.WriteString("</figure>")
+	return b.String()
+}
+
+// Run removes expired uploads until ctx is cancelled. Only the leader
+// process runs it.
+func (s *uploadService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.finishStalled(ctx); err != nil {
+			utils.Log.Errorf("Uploads: could not finish stalled uploads: %+v", err)
+		}
+
+		removed, err := s.ExpireAbandoned(ctx)
+		if err != nil {
+			utils.Log.Errorf("Uploads: expiry failed: %+v", err)
+		} else if removed > 0 {
+			utils.Log.Infof("Uploads: removed %d expired upload(s)", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// finishStalled finishes the uploads that have all their bytes but not
+// their file, because storing it failed or the process died. Clients take
+// an upload with all its bytes for done and do not send the last chunk again.
+func (s *uploadService) finishStalled(ctx context.Context) error {
+	now := time.Now()
+	var uploads []model.ResumableUpload
+	err := s.db.WithContext(ctx).
+		Where(`"offset" = length AND expires_at > ?`, now).
+		Where("(status = ? AND updated_at < ?) OR (status = ? AND updated_at < ?)",
+			model.UploadReceiving, now.Add(-time.Minute), model.UploadProcessing, now.Add(-staleAssembly)).
+		Order("updated_at").
+		Limit(uploadExpiryBatch).
+		Find(&uploads).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range uploads {
+		if ctx.Err() != nil {
+			return nil
+		}
+		// The media is credited to the uploader, unless they have since gone
+		var actor *model.User
+		var uploader model.User
+		switch err := s.db.First(&uploader, uploads[i].UserID).Error; {
+		case err == nil:
+			actor = &uploader
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		_, err := s.finish(&uploads[i], actor)
+		var refused *fiber.Error
+		if err != nil && !errors.As(err, &refused) {
+			utils.Log.Errorf("Uploads: could not finish %s: %+v", uploads[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// ExpireAbandoned removes the uploads that have not received a chunk within
+// config.TusExpiry, with their parts, and finished ones as long after they
+// finished. The files of finished uploads stay in the media library. It
+// returns how many uploads it removed.
+func (s *uploadService) ExpireAbandoned(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		now := time.Now()
+		var uploads []model.ResumableUpload
+		err := s.db.WithContext(ctx).
+			Where("expires_at < ?", now).
+			Where("status <> ? OR updated_at < ?", model.UploadProcessing, now.Add(-staleAssembly)).
+			Order("expires_at").
+			Limit(uploadExpiryBatch).
+			Find(&uploads).Error
+		if err != nil {
+			return removed, err
+		}
+
+		for i := range uploads {
+			if err := s.expire(ctx, &uploads[i]); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(uploads) < uploadExpiryBatch {
+			return removed, nil
+		}
+	}
+}
+
+// expire removes upload unless a chunk arrived since it was found expired.
+func (s *uploadService) expire(ctx context.Context, upload *model.ResumableUpload) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("expires_at < ?", time.Now()).
+			First(upload, "id = ?", upload.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.removeParts(ctx, upload); err != nil {
+			return err
+		}
+		return tx.Delete(upload).Error
+	})
+}
+
+// removeParts deletes the stored parts of upload, and the one a failed
+// write may have left after them.
+func (s *uploadService) removeParts(ctx context.Context, upload *model.ResumableUpload) error {
+	for n := 0; n <= upload.Parts; n++ {
+		if err := s.files.Delete(ctx, uploadPart(upload.ID, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parts reads the received bytes of upload, part after part.
+func (s *uploadService) parts(ctx context.Context, upload *model.ResumableUpload) io.ReadCloser {
+	return &partReader{ctx: ctx, files: s.files, id: upload.ID, parts: upload.Parts}
+}
+
+type partReader struct {
+	ctx     context.Context
+	files   storage.Storage
+	id      string
+	next    int
+	parts   int
+	current io.ReadCloser
+}
+
+func (r *partReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.next == r.parts {
+				return 0, io.EOF
+			}
+			part, err := r.files.Open(r.ctx, uploadPart(r.id, r.next))
+			if err != nil {
+				return 0, err
+			}
+			r.current = part
+			r.next++
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *partReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
+// findUpload loads the upload id into upload if it is actor's.
+func findUpload(db *gorm.DB, upload *model.ResumableUpload, id string, actor *model.User) error {
+	if actor == nil {
+		return fiber.ErrUnauthorized
+	}
+	err := db.Where("id = ? AND user_id = ?", id, actor.ID).First(upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "upload not found")
+	}
+	return err
+}
+
+// checkReceiving refuses chunks for an upload that has its file.
+func checkReceiving(upload *model.ResumableUpload) error {
+	switch {
+	case upload.Status == model.UploadReceiving:
+		return nil
+	case upload.Status == model.UploadProcessing && time.Since(upload.UpdatedAt) >= staleAssembly:
+		// Left processing by a process that died; the last chunk finishes it
+		return nil
+	case upload.Status == model.UploadFailed:
+		return fiber.NewError(fiber.StatusConflict, "the upload was refused: "+upload.Error)
+	case upload.Status == model.UploadProcessing:
+		return fiber.NewError(fiber.StatusConflict, "the upload is being processed")
+	}
+	return fiber.NewError(fiber.StatusConflict, "the upload is already complete")
+}
+
+func uploadGone() error {
+	return fiber.NewError(fiber.StatusGone, "the upload has expired")
+}
+
+// uploadHash is the SHA-256 of the bytes of upload received so far.
+func uploadHash(upload *model.ResumableUpload) (hash.Hash, error) {
+	sum := sha256.New()
+	if len(upload.HashState) > 0 {
+		if err := sum.(encoding.BinaryUnmarshaler).UnmarshalBinary(upload.HashState); err != nil {
+			return nil, fmt.Errorf("upload %s: %w", upload.ID, err)
+		}
+	}
+	return sum, nil
+}
+
+func uploadPart(id string, n int) string {
+	return fmt.Sprintf("%s%s-%05d", uploadPartPrefix, id, n)
+}
+
+func uploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma-separated
+// pairs of a key and, after a space, its value in base64.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	meta := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return meta, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("a key is empty")
+		}
+		if _, ok := meta[key]; ok {
+			return nil, fmt.Errorf("%s is given twice", key)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("the value of %s is not base64", key)
+		}
+		meta[key] = string(decoded)
+	}
+	return meta, nil
+}
+
+// uploadDetails are the media details sent in the upload metadata.
+func uploadDetails(meta map[string]string) *validation.MediaDetails {
+	details := &validation.MediaDetails{}
+	for key, field := range map[string]**string{
+		"alt":     &details.Alt,
+		"caption": &details.Caption,
+		"credit":  &details.Credit,
+		"license": &details.License,
+	} {
+		if value, ok := meta[key]; ok {
+			*field = &value
+		}
+	}
+	return details
+}
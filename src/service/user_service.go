@@ -5,6 +5,7 @@ import (
 	models "app/src/model"
 	"app/src/utils"
 	"app/src/validation"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -19,6 +20,7 @@ type UserService interface {
 	//GetByPhoneNumber(c *fiber.Ctx)
 	UpdateUser(c *fiber.Ctx, req *validation.UpdateUser2, id string) (*models.User, error)
 	DeleteUser(c *fiber.Ctx, id string) error
+	SetRole(c *fiber.Ctx, id string, role string, actor *models.User) (*models.User, error)
 	Login(email, password string) (string, error)
 }
 
@@ -40,6 +42,9 @@ func (s *userService) CreateUser(c *fiber.Ctx) (*models.User, error) {
 		return nil, err
 	}
 
+	// Roles grant editorial rights and are never taken from the request body
+	user.Role = ""
+
 	if err := s.DB.Create(&user).Error; err != nil {
 		return nil, err
 	}
@@ -144,6 +149,30 @@ func (s *userService) UpdateUser(c *fiber.Ctx, req *validation.UpdateUser2, id s
 	return user, nil
 }
 
+// SetRole gives a user one of config.Roles. Admins cannot change their own
+// role, so the last one cannot lock everybody out by accident.
+func (s *userService) SetRole(c *fiber.Ctx, id string, role string, actor *models.User) (*models.User, error) {
+	if _, ok := config.RoleRights[role]; !ok {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "unknown role "+role)
+	}
+
+	user, err := s.GetByUserId(c, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+		return nil, err
+	}
+	if actor != nil && actor.ID == user.ID {
+		return nil, fiber.NewError(fiber.StatusForbidden, "you cannot change your own role")
+	}
+
+	if err := s.DB.WithContext(c.Context()).Model(user).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // Delete
 func (s *userService) DeleteUser(c *fiber.Ctx, id string) error {
 	user := new(models.User)
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"app/src/model"
+	"app/src/utils"
+	"app/src/validation"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Views are buffered in memory and written in batches of at most
+// viewBatchSize rows. Past maxPendingViews further views are dropped until
+// the next flush rather than letting a traffic spike grow the buffer.
+const (
+	viewBatchSize   = 1000
+	maxPendingViews = 200000
+)
+
+var mostReadWindows = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+type ViewService interface {
+	Record(articleID int, ip, userAgent string)
+	Flush(ctx context.Context) error
+	Run(ctx context.Context, interval time.Duration)
+	MostRead(params *validation.QueryMostRead) ([]model.MostReadArticle, error)
+}
+
+type viewKey struct {
+	articleID int
+	visitor   string
+	bucket    int64
+}
+
+type viewService struct {
+	db     *gorm.DB
+	window time.Duration
+
+	mu        sync.Mutex
+	pending   map[viewKey]time.Time
+	lastPrune time.Time
+}
+
+// NewViewService counts each visitor at most once per article per window.
+func NewViewService(db *gorm.DB, window time.Duration) ViewService {
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+	return &viewService{
+		db:      db,
+		window:  window,
+		pending: make(map[viewKey]time.Time),
+	}
+}
+
+// Record notes a view without touching the database. Bots are ignored, and a
+// visitor (IP and User-Agent) is counted once per de-duplication window.
+func (s *viewService) Record(articleID int, ip, userAgent string) {
+	if utils.IsBot(userAgent) {
+		return
+	}
+
+	now := time.Now()
+	key := viewKey{
+		articleID: articleID,
+		visitor:   visitorID(ip, userAgent),
+		bucket:    now.UnixNano() / int64(s.window),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, seen := s.pending[key]; seen || len(s.pending) >= maxPendingViews {
+		return
+	}
+	s.pending[key] = now
+}
+
+// visitorID tells visitors apart by IP and User-Agent without keeping either.
+func visitorID(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(ip + "\x00" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Flush writes buffered views. Each batch is one statement: visits already
+// recorded by this or another process (under Prefork every child has its own
+// buffer) are skipped by the visit table's key, and only new ones are added
+// to the hourly stats and the article's view_count. Batches that fail are
+// kept for the next flush.
+func (s *viewService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[viewKey]time.Time, len(pending))
+	s.mu.Unlock()
+
+	keys := make([]viewKey, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+
+	for start := 0; start < len(keys); start += viewBatchSize {
+		end := min(start+viewBatchSize, len(keys))
+		if err := s.flushBatch(ctx, keys[start:end], pending); err != nil {
+			s.requeue(keys[start:], pending)
+			return err
+		}
+	}
+
+	return s.prune(ctx)
+}
+
+func (s *viewService) flushBatch(ctx context.Context, keys []viewKey, pending map[viewKey]time.Time) error {
+	rows := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys)*4)
+	for _, key := range keys {
+		rows = append(rows, "(?, ?, ?, ?::timestamptz)")
+		args = append(args, key.articleID, key.visitor, key.bucket, pending[key])
+	}
+
+	return s.db.WithContext(ctx).Exec(`
+		WITH fresh AS (
+			INSERT INTO article_visits (article_id, visitor, bucket, viewed_at)
+			VALUES `+strings.Join(rows, ", ")+`
+			ON CONFLICT DO NOTHING
+			RETURNING article_id, viewed_at
+		), hourly AS (
+			INSERT INTO article_view_stats (article_id, hour, views)
+			SELECT article_id, date_trunc('hour', viewed_at), count(*) FROM fresh GROUP BY 1, 2
+			ON CONFLICT (article_id, hour) DO UPDATE SET views = article_view_stats.views + excluded.views
+		)
+		UPDATE articles SET view_count = articles.view_count + fresh_counts.views
+		FROM (SELECT article_id, count(*) AS views FROM fresh GROUP BY 1) AS fresh_counts
+		WHERE articles.id = fresh_counts.article_id`, args...).Error
+}
+
+// requeue puts unwritten views back, unless the visitor was seen again in the
+// meantime.
+func (s *viewService) requeue(keys []viewKey, pending map[viewKey]time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if _, seen := s.pending[key]; !seen && len(s.pending) < maxPendingViews {
+			s.pending[key] = pending[key]
+		}
+	}
+}
+
+// prune drops visits whose window has passed, at most once per window.
+func (s *viewService) prune(ctx context.Context) error {
+	now := time.Now()
+	s.mu.Lock()
+	due := now.Sub(s.lastPrune) >= s.window
+	if due {
+		s.lastPrune = now
+	}
+	s.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).
+		Where("viewed_at < ?", now.Add(-2*s.window)).
+		Delete(&model.ArticleVisit{}).Error
+}
+
+// Run flushes buffered views every interval until ctx is cancelled. The last
+// flush on shutdown is left to the caller, see main.
+func (s *viewService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				utils.Log.Errorf("Failed to flush article views: %+v", err)
+			}
+		}
+	}
+}
+
+// MostRead ranks published articles by views in the last hour, day or week,
+// counted in whole hours.
+func (s *viewService) MostRead(params *validation.QueryMostRead) ([]model.MostReadArticle, error) {
+	period, ok := mostReadWindows[params.Window]
+	if !ok {
+		params.Window = "day"
+		period = mostReadWindows["day"]
+	}
+	if params.Limit <= 0 || params.Limit > 50 {
+		params.Limit = 10
+	}
+	since := time.Now().Add(-period).Truncate(time.Hour)
+
+	query := s.db.Model(&model.Article{}).
+		Scopes(published).
+		Joins("JOIN article_view_stats ON article_view_stats.article_id = articles.id AND article_view_stats.hour >= ?", since)
+	query, err := filterCategory(s.db, query, params.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []model.MostReadArticle
+	err = query.
+		Select("articles.*, sum(article_view_stats.views) AS views").
+		Group("articles.id").
+		Order("views desc, articles.id desc").
+		Limit(params.Limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]*model.Article, len(items))
+	for i := range items {
+		refs[i] = &items[i].Article
+	}
+	attachImages(s.db, refs...)
+	return items, nil
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TempPrefix starts the names of files the local store is still writing.
+// One left behind by a crash is an orphan like any other.
+const TempPrefix = ".upload-"
+
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocal stores files in dir, served from baseURL ("/uploads" when empty).
+func NewLocal(dir, baseURL string) Storage {
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+	return &localStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// path keeps keys inside the directory.
+func (s *localStorage) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.PutReader(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// PutReader writes the file under a temporary name and renames it, so it is
+// never seen half-written.
+func (s *localStorage) PutReader(_ context.Context, key string, r io.Reader, size int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, TempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(r, size+1))
+	if err == nil && written != size {
+		err = fmt.Errorf("storing %s: read %d bytes, expected %d", key, written, size)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStorage) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (s *localStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *localStorage) Stat(_ context.Context, key string) (*Info, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (s *localStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List walks the regular files of the directory, hidden ones included.
+func (s *localStorage) List(ctx context.Context, fn func(*Info) error) error {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		if err := fn(&Info{Key: e.Name(), Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *localStorage) URL(key string) string {
+	return s.baseURL + "/" + url.PathEscape(key)
+}
+
+// SignedURL is the public URL: the directory is served to anyone.
+func (s *localStorage) SignedURL(key string, _ time.Duration) (string, error) {
+	return s.URL(key), nil
+}
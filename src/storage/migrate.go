@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// MigrateResult counts what Migrate did, or would do on a dry run.
+type MigrateResult struct {
+	Copied  int
+	Skipped int
+	Removed int
+	Failed  int
+}
+
+// Migrate copies every file of from that to does not have at the same size.
+// With remove, each file is deleted from from once to has it. With dryRun
+// nothing is written and the result tells what would be. A file that fails
+// is reported to onError and counted; the rest are still moved.
+func Migrate(ctx context.Context, from, to Storage, remove, dryRun bool, onError func(key string, err error)) (MigrateResult, error) {
+	var result MigrateResult
+
+	err := from.List(ctx, func(info *Info) error {
+		// Half-written by the local store
+		if strings.HasPrefix(info.Key, TempPrefix) {
+			return nil
+		}
+
+		if err := migrateFile(ctx, from, to, info, remove, dryRun, &result); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.Failed++
+			onError(info.Key, err)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func migrateFile(ctx context.Context, from, to Storage, info *Info, remove, dryRun bool, result *MigrateResult) error {
+	existing, err := to.Stat(ctx, info.Key)
+	switch {
+	case err == nil && existing.Size == info.Size:
+		result.Skipped++
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return err
+	default:
+		if !dryRun {
+			// Streamed: videos may be too large to hold in memory
+			body, err := from.Open(ctx, info.Key)
+			if err != nil {
+				return err
+			}
+			err = to.PutReader(ctx, info.Key, body, info.Size, contentType(info.Key))
+			body.Close()
+			if err != nil {
+				return err
+			}
+		}
+		result.Copied++
+	}
+
+	if remove {
+		if !dryRun {
+			if err := from.Delete(ctx, info.Key); err != nil {
+				return err
+			}
+		}
+		result.Removed++
+	}
+	return nil
+}
+
+func contentType(key string) string {
+	if t := mime.TypeByExtension(strings.ToLower(filepath.Ext(key))); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
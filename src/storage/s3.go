@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config locates a bucket of an S3-compatible store. PathStyle addresses
+// it as endpoint/bucket rather than bucket.endpoint, as MinIO and most
+// self-hosted stores need. Keys are stored under Prefix.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string
+	PathStyle bool
+	// Where the bucket is served to readers, e.g. a CDN; the object URL
+	// itself when empty
+	PublicURL string
+}
+
+type s3Storage struct {
+	config   S3Config
+	endpoint *url.URL
+	signer   signer
+	client   *http.Client
+}
+
+// NewS3 talks to the bucket over the plain S3 REST API, signing requests
+// with the access key.
+func NewS3(cfg S3Config) (Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("S3 storage needs S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT %q must be an http or https URL", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Prefix != "" && !strings.HasSuffix(cfg.Prefix, "/") {
+		cfg.Prefix += "/"
+	}
+
+	// No overall timeout: a large file takes as long as it takes to send
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = time.Minute
+
+	return &s3Storage{
+		config:   cfg,
+		endpoint: endpoint,
+		signer:   signer{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: cfg.Region},
+		client:   &http.Client{Transport: transport},
+	}, nil
+}
+
+// url addresses the object at path within the bucket; "" is the bucket.
+func (s *s3Storage) url(path string) *url.URL {
+	u := *s.endpoint
+	if s.config.PathStyle {
+		path = strings.TrimSuffix(s.config.Bucket+"/"+path, "/")
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	u.Path = u.Path + "/" + path
+	u.RawPath = awsEscape(u.Path, true)
+	return &u
+}
+
+func (s *s3Storage) objectURL(key string) (*url.URL, error) {
+	if key == "" || strings.Contains(key, "/") {
+		return nil, fmt.Errorf("invalid storage key %q", key)
+	}
+	return s.url(s.config.Prefix + key), nil
+}
+
+func (s *s3Storage) do(ctx context.Context, method string, u *url.URL, body []byte, contentType string) (*http.Response, error) {
+	hash := sha256.Sum256(body)
+	return s.send(ctx, method, u, bytes.NewReader(body), int64(len(body)), hex.EncodeToString(hash[:]), contentType)
+}
+
+// send makes a request with a body of size bytes, which hashes to
+// payloadHash or is left unsigned.
+func (s *s3Storage) send(ctx context.Context, method string, u *url.URL, body io.Reader, size int64, payloadHash, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	s.signer.sign(req, payloadHash, time.Now())
+	return s.client.Do(req)
+}
+
+// s3Error reads the error S3 sent back. Not found wraps fs.ErrNotExist.
+func s3Error(resp *http.Response, method, key string) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	xml.Unmarshal(data, &body)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("s3: %s: %w", key, fs.ErrNotExist)
+	}
+	return fmt.Errorf("s3: %s %s: %s %s %s", method, key, resp.Status, body.Code, body.Message)
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := s.do(ctx, http.MethodPut, u, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, http.MethodPut, key)
+	}
+	return nil
+}
+
+// PutReader streams the body without hashing it first, which would mean
+// reading it twice.
+func (s *s3Storage) PutReader(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.send(ctx, http.MethodPut, u, io.LimitReader(r, size), size, unsignedPayload, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, http.MethodPut, key)
+	}
+	return nil
+}
+
+func (s *s3Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (s *s3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, u, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp, http.MethodGet, key)
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Storage) Stat(ctx context.Context, key string) (*Info, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, u, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, http.MethodHead, key)
+	}
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Info{Key: key, Size: size, ModTime: modTime}, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, u, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return s3Error(resp, http.MethodDelete, key)
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through the objects under the prefix. Objects further down,
+// in "directories" of their own, are not ours and are skipped.
+func (s *s3Storage) List(ctx context.Context, fn func(*Info) error) error {
+	token := ""
+	for {
+		u := s.url("")
+		query := url.Values{"list-type": {"2"}}
+		if s.config.Prefix != "" {
+			query.Set("prefix", s.config.Prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = canonicalQuery(query)
+
+		result, err := s.listPage(ctx, u)
+		if err != nil {
+			return err
+		}
+		for _, object := range result.Contents {
+			key := strings.TrimPrefix(object.Key, s.config.Prefix)
+			if key == "" || strings.Contains(key, "/") {
+				continue
+			}
+			if err := fn(&Info{Key: key, Size: object.Size, ModTime: object.LastModified}); err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *s3Storage) listPage(ctx context.Context, u *url.URL) (*listBucketResult, error) {
+	resp, err := s.do(ctx, http.MethodGet, u, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, "LIST", s.config.Bucket)
+	}
+	var result listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("s3: reading the object list: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *s3Storage) URL(key string) string {
+	if s.config.PublicURL != "" {
+		return strings.TrimSuffix(s.config.PublicURL, "/") + "/" + awsEscape(s.config.Prefix+key, true)
+	}
+	return s.url(s.config.Prefix + key).String()
+}
+
+func (s *s3Storage) SignedURL(key string, expiry time.Duration) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	return s.signer.presign(u, expiry, time.Now()), nil
+}
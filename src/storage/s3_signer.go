@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4, as S3 and the stores that copy its API expect:
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+const (
+	sigAlgorithm    = "AWS4-HMAC-SHA256"
+	sigService      = "s3"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// The longest a presigned URL may last
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+type signer struct {
+	accessKey, secretKey, region string
+}
+
+// sign adds the date, payload hash and Authorization headers to req, whose
+// body hashes to payloadHash.
+func (s *signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	canonicalHeaders, signedHeaders := canonicalHeaders(headers)
+
+	scope := s.scope(now)
+	signature := s.signature(now, scope, strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n"))
+
+	req.Header.Set("Authorization", sigAlgorithm+" Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// presign returns u with the query parameters that let anyone GET it until
+// expiry has passed.
+func (s *signer) presign(u *url.URL, expiry time.Duration, now time.Time) string {
+	expiry = min(max(expiry, time.Second), maxPresignExpiry)
+	scope := s.scope(now)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigAlgorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.UTC().Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	signature := s.signature(now, scope, strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n"))
+	query.Set("X-Amz-Signature", signature)
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
+
+func (s *signer) scope(now time.Time) string {
+	return now.UTC().Format("20060102") + "/" + s.region + "/" + sigService + "/aws4_request"
+}
+
+func (s *signer) signature(now time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := sigAlgorithm + "\n" + now.UTC().Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.UTC().Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, sigService)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalHeaders(headers map[string]string) (canonical, signed string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// canonicalQuery sorts and encodes query parameters the way SigV4 does,
+// which url.Values.Encode does not: spaces are %20, not +.
+func canonicalQuery(values url.Values) string {
+	type pair struct{ key, value string }
+	pairs := make([]pair, 0, len(values))
+	for key, vals := range values {
+		for _, v := range vals {
+			pairs = append(pairs, pair{awsEscape(key, false), awsEscape(v, false)})
+		}
+	}
+	// By name, then value; sorting the joined "name=value" strings would put
+	// "a-b=" before "a="
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+	return strings.Join(encoded, "&")
+}
+
+// awsEscape percent-encodes everything but the unreserved characters of
+// RFC 3986, and slashes when keepSlash is set.
+func awsEscape(s string, keepSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
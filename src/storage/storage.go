@@ -0,0 +1,67 @@
+// Package storage keeps uploaded files on the local disk or in an
+// S3-compatible object store, chosen by STORAGE_DRIVER. Files are addressed
+// by key, a plain file name; they are served at /uploads/<key> whichever
+// backend holds them, so stored links survive a move between backends.
+package storage
+
+import (
+	"app/src/config"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	Local = "local"
+	S3    = "s3"
+)
+
+// Storage is a flat store of files. Get, Open and Stat report a missing file
+// with an error wrapping fs.ErrNotExist; Delete of a missing file succeeds.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// PutReader stores the size bytes read from r, for files too large to
+	// hold in memory
+	PutReader(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Open streams a file; the caller closes it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (*Info, error)
+	Delete(ctx context.Context, key string) error
+	// List calls fn for every file, in no particular order, and stops at the
+	// first error fn returns
+	List(ctx context.Context, fn func(*Info) error) error
+	// URL is where a file can be fetched without credentials
+	URL(key string) string
+	// SignedURL is a link to a file that stops working after expiry, for
+	// stores that are not public
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+type Info struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// New opens the backend named driver, Local or S3, configured from the
+// STORAGE_ and S3_ settings.
+func New(driver string) (Storage, error) {
+	switch driver {
+	case Local:
+		return NewLocal(config.StorageLocalDir, config.StoragePublicURL), nil
+	case S3:
+		return NewS3(S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+			Prefix:    config.S3Prefix,
+			PathStyle: config.S3PathStyle,
+			PublicURL: config.StoragePublicURL,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage driver %q; use %q or %q", driver, Local, S3)
+}
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// banglishWords holds spellings the phonetic rules get wrong or cannot
+// guess, mostly English loanwords common in news copy.
+var banglishWords = map[string][]string{
+	"bajet":      {"বাজেট"},
+	"budget":     {"বাজেট"},
+	"cricket":    {"ক্রিকেট"},
+	"kriket":     {"ক্রিকেট"},
+	"football":   {"ফুটবল"},
+	"bangladesh": {"বাংলাদেশ"},
+	"dhaka":      {"ঢাকা"},
+	"chittagong": {"চট্টগ্রাম"},
+	"chottogram": {"চট্টগ্রাম"},
+	"election":   {"নির্বাচন"},
+	"nirbachon":  {"নির্বাচন"},
+	"sorkar":     {"সরকার"},
+	"sarkar":     {"সরকার"},
+	"police":     {"পুলিশ"},
+	"pulish":     {"পুলিশ"},
+	"hospital":   {"হাসপাতাল"},
+	"haspatal":   {"হাসপাতাল"},
+	"school":     {"স্কুল"},
+	"university": {"বিশ্ববিদ্যালয়"},
+	"price":      {"দাম"},
+	"dam":        {"দাম"},
+	"world":      {"বিশ্ব"},
+	"bishwo":     {"বিশ্ব"},
+	"biswa":      {"বিশ্ব"},
+	"cup":        {"কাপ"},
+	"trump":      {"ট্রাম্প"},
+	"india":      {"ভারত"},
+	"bharot":     {"ভারত"},
+	"america":    {"যুক্তরাষ্ট্র", "আমেরিকা"},
+	"rajniti":    {"রাজনীতি"},
+	"khela":      {"খেলা"},
+	"shastho":    {"স্বাস্থ্য"},
+	"sastho":     {"স্বাস্থ্য"},
+	"projukti":   {"প্রযুক্তি"},
+	"binodon":    {"বিনোদন"},
+	"brishti":    {"বৃষ্টি"},
+	"bonna":      {"বন্যা"},
+	"dengue":     {"ডেঙ্গু"},
+	"dengu":      {"ডেঙ্গু"},
+}
+
+// phonetic units, longest first. Each unit lists its Bengali renderings in
+// order of preference; vowels have a form after a consonant (sign) and a
+// standalone form.
+type banglishUnit struct {
+	latin     string
+	consonant []string
+	sign      []string
+	vowel     []string
+}
+
+var banglishUnits = []banglishUnit{
+	{latin: "chh", consonant: []string{"ছ"}},
+	{latin: "kkh", consonant: []string{"ক্ষ"}},
+	{latin: "kh", consonant: []string{"খ", "ক্ষ"}},
+	{latin: "gh", consonant: []string{"ঘ"}},
+	{latin: "ng", consonant: []string{"ং", "ঙ"}},
+	{latin: "ch", consonant: []string{"চ", "ছ"}},
+	{latin: "ck", consonant: []string{"ক"}},
+	{latin: "jh", consonant: []string{"ঝ"}},
+	{latin: "th", consonant: []string{"থ", "ঠ"}},
+	{latin: "dh", consonant: []string{"ধ", "ঢ"}},
+	{latin: "ph", consonant: []string{"ফ"}},
+	{latin: "bh", consonant: []string{"ভ"}},
+	{latin: "sh", consonant: []string{"শ", "ষ"}},
+	{latin: "rh", consonant: []string{"ঢ়"}},
+	{latin: "oi", sign: []string{"ৈ"}, vowel: []string{"ঐ"}},
+	{latin: "ou", sign: []string{"ৌ"}, vowel: []string{"ঔ"}},
+	{latin: "aa", sign: []string{"া"}, vowel: []string{"আ"}},
+	{latin: "ee", sign: []string{"ী"}, vowel: []string{"ঈ"}},
+	{latin: "oo", sign: []string{"ু", "ূ"}, vowel: []string{"উ"}},
+	{latin: "a", sign: []string{"া", ""}, vowel: []string{"আ", "অ"}},
+	{latin: "i", sign: []string{"ি", "ী"}, vowel: []string{"ই", "ঈ"}},
+	{latin: "u", sign: []string{"ু", "ূ"}, vowel: []string{"উ", "ঊ"}},
+	{latin: "e", sign: []string{"ে"}, vowel: []string{"এ"}},
+	{latin: "o", sign: []string{"", "ো"}, vowel: []string{"অ", "ও"}},
+	{latin: "y", consonant: []string{"য়", "য"}},
+	{latin: "w", consonant: []string{"ও", "ব"}},
+	{latin: "k", consonant: []string{"ক"}},
+	{latin: "q", consonant: []string{"ক"}},
+	{latin: "c", consonant: []string{"ক", "স"}},
+	{latin: "g", consonant: []string{"গ"}},
+	{latin: "j", consonant: []string{"জ", "য"}},
+	{latin: "z", consonant: []string{"জ", "য"}},
+	{latin: "t", consonant: []string{"ত", "ট"}},
+	{latin: "d", consonant: []string{"দ", "ড"}},
+	{latin: "n", consonant: []string{"ন", "ণ"}},
+	{latin: "p", consonant: []string{"প"}},
+	{latin: "f", consonant: []string{"ফ"}},
+	{latin: "b", consonant: []string{"ব"}},
+	{latin: "v", consonant: []string{"ভ"}},
+	{latin: "m", consonant: []string{"ম"}},
+	{latin: "r", consonant: []string{"র", "ড়"}},
+	{latin: "l", consonant: []string{"ল"}},
+	{latin: "s", consonant: []string{"স", "শ"}},
+	{latin: "h", consonant: []string{"হ"}},
+	{latin: "x", consonant: []string{"ক্স"}},
+}
+
+// banglishPhala marks consonants that usually attach to the one before them
+// (প্র, ক্য, ক্ল, স্ব) rather than taking an inherent vowel.
+var banglishPhala = map[string]bool{"র": true, "য": true, "ল": true, "ব": true}
+
+// banglishBeam bounds how many partial spellings are kept per step.
+const banglishBeam = 16
+
+type banglishGuess struct {
+	text          string
+	cost          int
+	lastConsonant bool
+}
+
+// IsRomanized reports whether s is written in Latin letters only, which is
+// how readers type Bengali words on keyboards without a Bengali layout.
+func IsRomanized(s string) bool {
+	hasLatin := false
+	for _, r := range s {
+		if unicode.Is(unicode.Bengali, r) {
+			return false
+		}
+		if unicode.Is(unicode.Latin, r) {
+			hasLatin = true
+		}
+	}
+	return hasLatin
+}
+
+// BanglishCandidates returns up to max Bengali spellings for a romanised
+// word, most likely first. Known words come from a small dictionary; the rest
+// are guessed phonetically, trying the common ambiguities (ত/ট, দ/ড, স/শ,
+// ি/ী, conjunct or inherent vowel) and keeping the cheapest combinations.
+func BanglishCandidates(word string, max int) []string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" || max <= 0 {
+		return nil
+	}
+
+	var out []string
+	seen := map[string]bool{}
+	add := func(s string) {
+		s = NormalizeText(s)
+		if s != "" && !seen[s] && len(out) < max {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+
+	for _, known := range banglishWords[word] {
+		add(known)
+	}
+
+	for _, guess := range banglishGuesses(word) {
+		add(guess)
+	}
+	return out
+}
+
+func banglishGuesses(word string) []string {
+	beam := []banglishGuess{{}}
+
+	for pos := 0; pos < len(word); {
+		unit, ok := matchBanglishUnit(word[pos:])
+		if !ok {
+			// Not a letter we can spell; skip it
+			pos++
+			continue
+		}
+		pos += len(unit.latin)
+
+		var next []banglishGuess
+		for _, g := range beam {
+			switch {
+			case unit.consonant != nil:
+				for i, c := range unit.consonant {
+					// ড় never starts a word
+					if c == "ড়" && g.text == "" {
+						continue
+					}
+					plain, joined := i, i+1
+					if banglishPhala[c] {
+						plain, joined = i+1, i
+					}
+					next = append(next, banglishGuess{text: g.text + c, cost: g.cost + plain, lastConsonant: true})
+					// Two consonants in a row may form a conjunct
+					if g.lastConsonant && banglishJoins(g.text, c) {
+						next = append(next, banglishGuess{text: g.text + "্" + c, cost: g.cost + joined, lastConsonant: true})
+					}
+				}
+			case g.lastConsonant:
+				for i, v := range unit.sign {
+					next = append(next, banglishGuess{text: g.text + v, cost: g.cost + i})
+				}
+			default:
+				for i, v := range unit.vowel {
+					next = append(next, banglishGuess{text: g.text + v, cost: g.cost + i})
+				}
+			}
+		}
+
+		sort.SliceStable(next, func(i, j int) bool { return next[i].cost < next[j].cost })
+		if len(next) > banglishBeam {
+			next = next[:banglishBeam]
+		}
+		beam = next
+	}
+
+	guesses := make([]string, 0, len(beam))
+	for _, g := range beam {
+		guesses = append(guesses, g.text)
+	}
+	return guesses
+}
+
+// banglishJoins reports whether c can form a conjunct with the consonant that
+// ends prev.
+func banglishJoins(prev, c string) bool {
+	if c == "ং" || c == "য়" || c == "ড়" {
+		return false
+	}
+	return !strings.HasSuffix(prev, "ং") && !strings.HasSuffix(prev, "ড়")
+}
+
+func matchBanglishUnit(s string) (banglishUnit, bool) {
+	for _, u := range banglishUnits {
+		if strings.HasPrefix(s, u.latin) {
+			return u, true
+		}
+	}
+	return banglishUnit{}, false
+}
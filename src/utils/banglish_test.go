@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRomanized(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"dhaka bonna", true},
+		{"Dhaka 2024", true},
+		{"ঢাকা", false},
+		{"dhaka ঢাকা", false},
+		{"2024", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRomanized(tt.in))
+		})
+	}
+}
+
+func TestBanglishCandidates(t *testing.T) {
+	tests := []struct {
+		word  string
+		first string
+		also  []string
+	}{
+		// From the dictionary, before any guess
+		{word: "Bajet", first: "বাজেট"},
+		{word: "america", first: "যুক্তরাষ্ট্র", also: []string{"আমেরিকা"}},
+		// Guessed: inherent vowels, aspirates, conjuncts and phala
+		{word: "amar", first: "আমার"},
+		{word: "ami", first: "আমি", also: []string{"আমী"}},
+		{word: "khobor", first: "খবর"},
+		{word: "kotha", first: "কথা"},
+		{word: "prothom", first: "প্রথম"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got := BanglishCandidates(tt.word, 5)
+			if assert.NotEmpty(t, got) {
+				assert.Equal(t, tt.first, got[0])
+			}
+			assert.LessOrEqual(t, len(got), 5)
+			for _, want := range tt.also {
+				assert.Contains(t, got, want)
+			}
+		})
+	}
+}
+
+func TestBanglishCandidatesNothingToSpell(t *testing.T) {
+	assert.Empty(t, BanglishCandidates("", 5))
+	assert.Empty(t, BanglishCandidates("123", 5))
+	assert.Empty(t, BanglishCandidates("dhaka", 0))
+}
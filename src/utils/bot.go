@@ -0,0 +1,29 @@
+package utils
+
+import "strings"
+
+// botAgents are User-Agent fragments of crawlers, link previewers, monitors
+// and command-line fetchers. HTTP libraries that apps are built on (okhttp,
+// axios, node-fetch) are left out, as readers use those apps.
+var botAgents = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit",
+	"facebookcatalog", "embedly", "preview", "whatsapp", "telegram", "skype",
+	"headless", "phantomjs", "lighthouse", "pingdom", "uptime", "monitor",
+	"curl", "wget", "python-requests", "python-urllib", "go-http-client",
+	"libwww",
+}
+
+// IsBot reports whether a request with this User-Agent comes from a bot
+// rather than a reader. Requests without a User-Agent count as bots.
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, fragment := range botAgents {
+		if strings.Contains(ua, fragment) {
+			return true
+		}
+	}
+	return false
+}
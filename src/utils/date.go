@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var banglaMonths = [...]string{
+	"জানুয়ারি", "ফেব্রুয়ারি", "মার্চ", "এপ্রিল", "মে", "জুন",
+	"জুলাই", "আগস্ট", "সেপ্টেম্বর", "অক্টোবর", "নভেম্বর", "ডিসেম্বর",
+}
+
+// BanglaDigits writes the ASCII digits in s as Bengali digits.
+func BanglaDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '০' + (r - '0')
+		}
+		return r
+	}, s)
+}
+
+// BanglaDate formats t the way the site shows dates, e.g. "১৭ অক্টোবর ২০২৬".
+func BanglaDate(t time.Time) string {
+	return BanglaDigits(strconv.Itoa(t.Day())) + " " + banglaMonths[t.Month()-1] + " " + BanglaDigits(strconv.Itoa(t.Year()))
+}
@@ -0,0 +1,181 @@
+package utils
+
+import "strings"
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffOp is one run of a word-level diff.
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxDiffEdits bounds the Myers search. Beyond it the texts are treated as
+// rewritten wholesale, which keeps memory bounded (roughly maxDiffEdits²
+// ints) for unrelated revisions.
+const maxDiffEdits = 1000
+
+// DiffWords compares two texts word by word (see Tokenize) after NFC
+// normalisation and returns the merged runs needed to turn a into b.
+func DiffWords(a, b string) []DiffOp {
+	x := Tokenize(NormalizeText(a))
+	y := Tokenize(NormalizeText(b))
+
+	// Trim the common prefix and suffix; edits are usually local
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	var ops []DiffOp
+	ops = appendOp(ops, DiffEqual, x[:prefix]...)
+	ops = append(ops, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	ops = appendOp(ops, DiffEqual, x[len(x)-suffix:]...)
+
+	return mergeOps(ops)
+}
+
+// myers implements the greedy O((N+M)D) shortest edit script algorithm.
+func myers(x, y []string) []DiffOp {
+	n, m := len(x), len(y)
+	if n == 0 || m == 0 {
+		return replaceAll(x, y)
+	}
+
+	limit := n + m
+	if limit > maxDiffEdits {
+		limit = maxDiffEdits
+	}
+
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d] holds v[-d-1..d+1] as it was before step d
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				px = v[offset+k+1]
+			} else {
+				px = v[offset+k-1] + 1
+			}
+			py := px - k
+			for px < n && py < m && x[px] == y[py] {
+				px++
+				py++
+			}
+			v[offset+k] = px
+			if px >= n && py >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return replaceAll(x, y)
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var reversed []DiffOp
+	px, py := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		at := func(k int) int { return vd[k+d+1] }
+		k := px - py
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for px > prevX && py > prevY {
+			px--
+			py--
+			reversed = append(reversed, DiffOp{Op: DiffEqual, Text: x[px]})
+		}
+
+		if d > 0 {
+			if px == prevX {
+				py--
+				reversed = append(reversed, DiffOp{Op: DiffInsert, Text: y[py]})
+			} else {
+				px--
+				reversed = append(reversed, DiffOp{Op: DiffDelete, Text: x[px]})
+			}
+		}
+	}
+
+	ops := make([]DiffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
+
+func replaceAll(x, y []string) []DiffOp {
+	var ops []DiffOp
+	ops = appendOp(ops, DiffDelete, x...)
+	ops = appendOp(ops, DiffInsert, y...)
+	return ops
+}
+
+func appendOp(ops []DiffOp, op string, tokens ...string) []DiffOp {
+	if len(tokens) == 0 {
+		return ops
+	}
+	return append(ops, DiffOp{Op: op, Text: strings.Join(tokens, "")})
+}
+
+// mergeOps joins adjacent runs of the same kind and, within a changed region,
+// orders deletions before insertions.
+func mergeOps(ops []DiffOp) []DiffOp {
+	var merged []DiffOp
+	var del, ins strings.Builder
+
+	flushChanges := func() {
+		if del.Len() > 0 {
+			merged = append(merged, DiffOp{Op: DiffDelete, Text: del.String()})
+			del.Reset()
+		}
+		if ins.Len() > 0 {
+			merged = append(merged, DiffOp{Op: DiffInsert, Text: ins.String()})
+			ins.Reset()
+		}
+	}
+
+	for _, op := range ops {
+		switch op.Op {
+		case DiffDelete:
+			del.WriteString(op.Text)
+		case DiffInsert:
+			ins.WriteString(op.Text)
+		default:
+			flushChanges()
+			if len(merged) > 0 && merged[len(merged)-1].Op == DiffEqual {
+				merged[len(merged)-1].Text += op.Text
+			} else {
+				merged = append(merged, op)
+			}
+		}
+	}
+	flushChanges()
+
+	return merged
+}
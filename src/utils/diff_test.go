@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffOp
+	}{
+		{
+			name: "both empty",
+			want: nil,
+		},
+		{
+			name: "empty old side",
+			b:    "নতুন খবর",
+			want: []DiffOp{{Op: DiffInsert, Text: "নতুন খবর"}},
+		},
+		{
+			name: "empty new side",
+			a:    "পুরনো খবর",
+			want: []DiffOp{{Op: DiffDelete, Text: "পুরনো খবর"}},
+		},
+		{
+			name: "identical",
+			a:    "ঢাকায় বৃষ্টি হচ্ছে",
+			b:    "ঢাকায় বৃষ্টি হচ্ছে",
+			want: []DiffOp{{Op: DiffEqual, Text: "ঢাকায় বৃষ্টি হচ্ছে"}},
+		},
+		{
+			name: "insert in the middle",
+			a:    "ঢাকায় বৃষ্টি হচ্ছে",
+			b:    "ঢাকায় ভারী বৃষ্টি হচ্ছে",
+			want: []DiffOp{
+				{Op: DiffEqual, Text: "ঢাকায় "},
+				{Op: DiffInsert, Text: "ভারী "},
+				{Op: DiffEqual, Text: "বৃষ্টি হচ্ছে"},
+			},
+		},
+		{
+			name: "delete in the middle",
+			a:    "the quick brown fox",
+			b:    "the brown fox",
+			want: []DiffOp{
+				{Op: DiffEqual, Text: "the "},
+				{Op: DiffDelete, Text: "quick "},
+				{Op: DiffEqual, Text: "brown fox"},
+			},
+		},
+		{
+			name: "replaced word",
+			a:    "সরকার বলেছে",
+			b:    "মন্ত্রী বলেছে",
+			want: []DiffOp{
+				{Op: DiffDelete, Text: "সরকার"},
+				{Op: DiffInsert, Text: "মন্ত্রী"},
+				{Op: DiffEqual, Text: " বলেছে"},
+			},
+		},
+		{
+			// Only the vowel sign differs: the whole word changes, never
+			// the sign apart from its letter
+			name: "vowel sign stays with its letter",
+			a:    "কাল আসবে",
+			b:    "কল আসবে",
+			want: []DiffOp{
+				{Op: DiffDelete, Text: "কাল"},
+				{Op: DiffInsert, Text: "কল"},
+				{Op: DiffEqual, Text: " আসবে"},
+			},
+		},
+		{
+			// ক্ষ is ক, hasanta, ষ: one word, however the conjunct changes
+			name: "conjunct stays whole",
+			a:    "পক্ষ নিয়েছে",
+			b:    "পক্ক নিয়েছে",
+			want: []DiffOp{
+				{Op: DiffDelete, Text: "পক্ষ"},
+				{Op: DiffInsert, Text: "পক্ক"},
+				{Op: DiffEqual, Text: " নিয়েছে"},
+			},
+		},
+		{
+			// য় typed as one code point equals য + nukta, its NFC form
+			name: "composed and decomposed letters are equal",
+			a:    "নি\u09df\u09c7",
+			b:    "নি\u09af\u09bc\u09c7",
+			want: []DiffOp{{Op: DiffEqual, Text: "নি\u09af\u09bc\u09c7"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DiffWords(tt.a, tt.b))
+		})
+	}
+}
+
+func TestDiffWordsRebuildsBothSides(t *testing.T) {
+	a := "প্রধানমন্ত্রী আজ সংসদে বাজেট পেশ করেছেন। বিরোধী দল ওয়াকআউট করে।"
+	b := "অর্থমন্ত্রী আজ জাতীয় সংসদে বাজেট পেশ করেন। বিরোধী দল সভা বর্জন করে।"
+
+	var oldText, newText strings.Builder
+	for _, op := range DiffWords(a, b) {
+		if op.Op != DiffInsert {
+			oldText.WriteString(op.Text)
+		}
+		if op.Op != DiffDelete {
+			newText.WriteString(op.Text)
+		}
+	}
+	assert.Equal(t, NormalizeText(a), oldText.String())
+	assert.Equal(t, NormalizeText(b), newText.String())
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif"  // register the decoder for ImageSize
+	_ "image/jpeg" // register the decoder for ImageSize
+	_ "image/png"  // register the decoder for ImageSize
+
+	_ "golang.org/x/image/webp" // register the decoder for image variants
+)
+
+// Image formats accepted for upload, named by the extension they are
+// stored under.
+const (
+	ImageJPEG = "jpg"
+	ImagePNG  = "png"
+	ImageGIF  = "gif"
+	ImageWebP = "webp"
+	ImageAVIF = "avif"
+)
+
+// ImageMIME maps an image format to its content type.
+var ImageMIME = map[string]string{
+	ImageJPEG: "image/jpeg",
+	ImagePNG:  "image/png",
+	ImageGIF:  "image/gif",
+	ImageWebP: "image/webp",
+	ImageAVIF: "image/avif",
+}
+
+var errImageHeader = errors.New("image header is damaged")
+
+// SniffImage names the format of data from its leading magic bytes, or
+// returns "" when it is none of the accepted formats. The file name and the
+// browser's content type are never trusted.
+func SniffImage(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return ImageJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ImagePNG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return ImageGIF
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return ImageWebP
+	case isAVIF(data):
+		return ImageAVIF
+	}
+	return ""
+}
+
+// isAVIF looks for an avif (still) or avis (sequence) brand in the leading
+// ftyp box.
+func isAVIF(data []byte) bool {
+	if len(data) < 16 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	size := int(binary.BigEndian.Uint32(data))
+	if size < 16 || size > len(data) {
+		return false
+	}
+	// Major brand, minor version, then compatible brands
+	for i := 8; i+4 <= size; i += 4 {
+		if i == 12 {
+			continue
+		}
+		if brand := string(data[i : i+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageSize reads the pixel dimensions of an image of the given format
+// from its headers, without decoding the pixels.
+func ImageSize(data []byte, format string) (width, height int, err error) {
+	switch format {
+	case ImageWebP:
+		return webpSize(data)
+	case ImageAVIF:
+		return avifSize(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+func webpSize(data []byte) (int, int, error) {
+	for _, chunk := range riffChunks(data) {
+		d := chunk.data
+		switch chunk.id {
+		case "VP8X":
+			if len(d) < 10 {
+				return 0, 0, errImageHeader
+			}
+			return int(uint24(d[4:])) + 1, int(uint24(d[7:])) + 1, nil
+		case "VP8 ":
+			// Frame tag, then the 9d 01 2a start code and 14-bit sizes
+			if len(d) < 10 || d[3] != 0x9d || d[4] != 0x01 || d[5] != 0x2a {
+				return 0, 0, errImageHeader
+			}
+			return int(binary.LittleEndian.Uint16(d[6:]) & 0x3fff), int(binary.LittleEndian.Uint16(d[8:]) & 0x3fff), nil
+		case "VP8L":
+			if len(d) < 5 || d[0] != 0x2f {
+				return 0, 0, errImageHeader
+			}
+			bits := binary.LittleEndian.Uint32(d[1:])
+			return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+		}
+	}
+	return 0, 0, errImageHeader
+}
+
+// avifSize returns the largest image spatial extent (ispe) property, which
+// covers the primary image as well as any grid or thumbnail items.
+func avifSize(data []byte) (int, int, error) {
+	meta, ok := findBox(isoBoxes(data), "meta")
+	if !ok || len(meta.data) < 4 {
+		return 0, 0, errImageHeader
+	}
+	iprp, ok := findBox(isoBoxes(meta.data[4:]), "iprp")
+	if !ok {
+		return 0, 0, errImageHeader
+	}
+	ipco, ok := findBox(isoBoxes(iprp.data), "ipco")
+	if !ok {
+		return 0, 0, errImageHeader
+	}
+
+	width, height := 0, 0
+	for _, prop := range isoBoxes(ipco.data) {
+		if prop.kind != "ispe" || len(prop.data) < 12 {
+			continue
+		}
+		w := int(binary.BigEndian.Uint32(prop.data[4:]))
+		h := int(binary.BigEndian.Uint32(prop.data[8:]))
+		if w*h > width*height {
+			width, height = w, h
+		}
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, errImageHeader
+	}
+	return width, height, nil
+}
+
+// riffChunk is a chunk of a WebP file; start and end span its header, data
+// and padding within the file.
+type riffChunk struct {
+	id         string
+	data       []byte
+	start, end int
+}
+
+// riffChunks lists the chunks after the RIFF/WEBP header, stopping at the
+// first one that does not fit.
+func riffChunks(data []byte) []riffChunk {
+	var chunks []riffChunk
+	for pos := 12; pos+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if end > len(data) {
+			break
+		}
+		chunk := riffChunk{id: string(data[pos : pos+4]), data: data[pos+8 : end], start: pos}
+		if size%2 == 1 && end < len(data) {
+			end++
+		}
+		chunk.end = end
+		chunks = append(chunks, chunk)
+		pos = end
+	}
+	return chunks
+}
+
+// isoBox is a box of an ISO base media file (AVIF); offset is where its
+// data starts within the slice it was read from.
+type isoBox struct {
+	kind   string
+	data   []byte
+	offset int
+}
+
+// isoBoxes lists the boxes in data, stopping at the first one that does not
+// fit. A size of 0 means the box runs to the end.
+func isoBoxes(data []byte) []isoBox {
+	var boxes []isoBox
+	for pos := 0; pos+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[pos:]))
+		header := 8
+		switch size {
+		case 0:
+			size = uint64(len(data) - pos)
+		case 1:
+			if pos+16 > len(data) {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(data[pos+8:])
+			header = 16
+		}
+		if size < uint64(header) || size > uint64(len(data)-pos) {
+			return boxes
+		}
+		end := pos + int(size)
+		boxes = append(boxes, isoBox{kind: string(data[pos+4 : pos+8]), data: data[pos+header : end], offset: pos + header})
+		pos = end
+	}
+	return boxes
+}
+
+func findBox(boxes []isoBox, kind string) (isoBox, bool) {
+	for _, box := range boxes {
+		if box.kind == kind {
+			return box, true
+		}
+	}
+	return isoBox{}, false
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
@@ -0,0 +1,465 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// exifOrientationTag is the EXIF tag browsers read to show a photo upright.
+const exifOrientationTag = 0x0112
+
+// StripImageMetadata removes EXIF (camera, GPS position, time taken), XMP,
+// IPTC and text comments from an image without re-encoding it, and drops
+// anything hidden after the end of the image. For JPEG, PNG and WebP the
+// EXIF orientation is kept in a minimal EXIF block of its own, so photos
+// taken on a phone still show upright. AVIF metadata items are blanked in
+// place, as removing them would move the image data.
+func StripImageMetadata(data []byte, format string) ([]byte, error) {
+	switch format {
+	case ImageJPEG:
+		return stripJPEG(data)
+	case ImagePNG:
+		return stripPNG(data)
+	case ImageGIF:
+		return stripGIF(data)
+	case ImageWebP:
+		return stripWebP(data)
+	case ImageAVIF:
+		return stripAVIF(data)
+	}
+	return nil, errImageHeader
+}
+
+// stripJPEG keeps the JFIF header, the ICC colour profile and the Adobe
+// colour marker and drops every other APPn segment and comments. Everything
+// from the start of scan on is image data and is copied as it is.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+
+	orientation := 1
+	exifWritten := false
+	writeExif := func() {
+		if !exifWritten && orientation != 1 {
+			tiff := minimalExif(orientation)
+			out = append(out, 0xFF, 0xE1, 0, 0)
+			binary.BigEndian.PutUint16(out[len(out)-2:], uint16(2+6+len(tiff)))
+			out = append(out, "Exif\x00\x00"...)
+			out = append(out, tiff...)
+		}
+		exifWritten = true
+	}
+
+	for pos := 2; pos+1 < len(data); {
+		if data[pos] != 0xFF {
+			return nil, errImageHeader
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF:
+			// Fill byte
+			pos++
+			continue
+		case marker == 0x01 || marker >= 0xD0 && marker <= 0xD7:
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		case marker == 0xD9:
+			return append(out, 0xFF, 0xD9), nil
+		}
+
+		if pos+4 > len(data) {
+			return nil, errImageHeader
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) || end < pos+4 {
+			return nil, errImageHeader
+		}
+		payload := data[pos+4 : end]
+
+		if marker == 0xDA {
+			writeExif()
+			rest := data[pos:]
+			if i := bytes.LastIndex(rest, []byte{0xFF, 0xD9}); i >= 0 {
+				rest = rest[:i+2]
+			}
+			return append(out, rest...), nil
+		}
+
+		if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			orientation = exifOrientation(payload[6:])
+		}
+
+		keep := true
+		switch {
+		case marker == 0xE0:
+		case marker == 0xE2:
+			keep = bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+		case marker == 0xEE:
+			keep = bytes.HasPrefix(payload, []byte("Adobe"))
+		case marker >= 0xE1 && marker <= 0xEF, marker == 0xFE:
+			keep = false
+		}
+		if keep {
+			if marker != 0xE0 {
+				writeExif()
+			}
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return nil, errImageHeader
+}
+
+// stripPNG drops the eXIf, text and timestamp chunks and anything after
+// IEND.
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+
+	for pos := 8; pos+12 <= len(data); {
+		end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:]))
+		if end > len(data) || end < pos+12 {
+			return nil, errImageHeader
+		}
+		kind := string(data[pos+4 : pos+8])
+
+		switch kind {
+		case "eXIf":
+			if orientation := exifOrientation(data[pos+8 : end-4]); orientation != 1 {
+				out = appendPNGChunk(out, kind, minimalExif(orientation))
+			}
+		case "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[pos:end]...)
+		}
+
+		if kind == "IEND" {
+			return out, nil
+		}
+		pos = end
+	}
+	return nil, errImageHeader
+}
+
+func appendPNGChunk(out []byte, kind string, data []byte) []byte {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	copy(header[4:], kind)
+	out = append(out, header[:]...)
+	out = append(out, data...)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	return binary.BigEndian.AppendUint32(out, crc.Sum32())
+}
+
+// stripGIF drops comment extensions and application extensions other than
+// the animation loop count (XMP is stored as one), and anything after the
+// trailer.
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 {
+		return nil, errImageHeader
+	}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1)
+	}
+	if pos > len(data) {
+		return nil, errImageHeader
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:pos]...)
+
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21:
+			if pos+2 > len(data) {
+				return nil, errImageHeader
+			}
+			end, err := skipGIFBlocks(data, pos+2)
+			if err != nil {
+				return nil, err
+			}
+			label := data[pos+1]
+			keep := label != 0xFE
+			if label == 0xFF {
+				app := data[pos+2 : end]
+				keep = bytes.HasPrefix(app, []byte("\x0bNETSCAPE2.0")) || bytes.HasPrefix(app, []byte("\x0bANIMEXTS1.0"))
+			}
+			if keep {
+				out = append(out, data[pos:end]...)
+			}
+			pos = end
+		case 0x2C:
+			if pos+11 > len(data) {
+				return nil, errImageHeader
+			}
+			start := pos
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			// LZW minimum code size, then the image data
+			end, err := skipGIFBlocks(data, pos+1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, data[start:end]...)
+			pos = end
+		case 0x3B:
+			return append(out, 0x3B), nil
+		default:
+			return nil, errImageHeader
+		}
+	}
+	return nil, errImageHeader
+}
+
+// skipGIFBlocks returns the position after the data sub-blocks at pos.
+func skipGIFBlocks(data []byte, pos int) (int, error) {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+	return 0, errImageHeader
+}
+
+// stripWebP drops the EXIF and XMP chunks and updates the extended header's
+// flags and the RIFF size to match.
+func stripWebP(data []byte) ([]byte, error) {
+	chunks := riffChunks(data)
+	if len(chunks) == 0 {
+		return nil, errImageHeader
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	orientation := 1
+	vp8x := -1
+	for _, chunk := range chunks {
+		switch chunk.id {
+		case "EXIF":
+			orientation = exifOrientation(bytes.TrimPrefix(chunk.data, []byte("Exif\x00\x00")))
+			continue
+		case "XMP ":
+			continue
+		case "VP8X":
+			vp8x = len(out) + 8
+		}
+		out = append(out, data[chunk.start:chunk.end]...)
+	}
+
+	if vp8x >= 0 {
+		// Extended format flags: 0x08 EXIF, 0x04 XMP
+		out[vp8x] &^= 0x0C
+		if orientation != 1 {
+			out[vp8x] |= 0x08
+			tiff := minimalExif(orientation)
+			out = append(out, "EXIF"...)
+			out = binary.LittleEndian.AppendUint32(out, uint32(len(tiff)))
+			out = append(out, tiff...)
+		}
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// stripAVIF overwrites the data of Exif items and XMP (application/rdf+xml)
+// items with zeros. The items stay, so no offsets in the file change. AVIF
+// carries its orientation in its own properties, not in EXIF.
+func stripAVIF(data []byte) ([]byte, error) {
+	out := append([]byte(nil), data...)
+
+	meta, ok := findBox(isoBoxes(out), "meta")
+	if !ok || len(meta.data) < 4 {
+		return nil, errImageHeader
+	}
+	children := isoBoxes(meta.data[4:])
+	base := meta.offset + 4
+
+	scrub := map[uint64]bool{}
+	if iinf, ok := findBox(children, "iinf"); ok && len(iinf.data) >= 4 {
+		r := &boxReader{data: iinf.data, pos: 4}
+		if iinf.data[0] == 0 {
+			r.uint(2)
+		} else {
+			r.uint(4)
+		}
+		for _, infe := range isoBoxes(iinf.data[r.pos:]) {
+			if id, kind, contentType := itemInfo(infe); kind == "Exif" || kind == "mime" && contentType == "application/rdf+xml" {
+				scrub[id] = true
+			}
+		}
+	}
+	if len(scrub) == 0 {
+		return out, nil
+	}
+
+	iloc, ok := findBox(children, "iloc")
+	if !ok || len(iloc.data) < 8 {
+		return nil, errImageHeader
+	}
+	idat := -1
+	if box, ok := findBox(children, "idat"); ok {
+		idat = base + box.offset
+	}
+
+	version := iloc.data[0]
+	r := &boxReader{data: iloc.data, pos: 4}
+	sizes := r.uint(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0F)
+	sizes = r.uint(1)
+	baseSize, indexSize := int(sizes>>4), 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0x0F)
+	}
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count := r.uint(idSize)
+	for i := uint64(0); i < count && !r.short; i++ {
+		id := r.uint(idSize)
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = r.uint(2) & 0x0F
+		}
+		r.uint(2) // data reference index
+		itemBase := r.uint(baseSize)
+		extents := r.uint(2)
+		for e := uint64(0); e < extents && !r.short; e++ {
+			r.uint(indexSize)
+			offset := itemBase + r.uint(offsetSize)
+			length := r.uint(lengthSize)
+			if !scrub[id] || length == 0 {
+				continue
+			}
+			switch {
+			case method == 0:
+			case method == 1 && idat >= 0:
+				offset += uint64(idat)
+			default:
+				continue
+			}
+			if offset > uint64(len(out)) || length > uint64(len(out))-offset {
+				return nil, errImageHeader
+			}
+			clear(out[offset : offset+length])
+		}
+	}
+	if r.short {
+		return nil, errImageHeader
+	}
+	return out, nil
+}
+
+// itemInfo reads an item info entry (infe, version 2 or 3): the item's id,
+// its type and, for mime items, the content type.
+func itemInfo(infe isoBox) (id uint64, kind, contentType string) {
+	if infe.kind != "infe" || len(infe.data) < 4 || infe.data[0] < 2 {
+		return 0, "", ""
+	}
+	r := &boxReader{data: infe.data, pos: 4}
+	if infe.data[0] == 2 {
+		id = r.uint(2)
+	} else {
+		id = r.uint(4)
+	}
+	r.uint(2) // protection index
+	if r.short || r.pos+4 > len(r.data) {
+		return 0, "", ""
+	}
+	kind = string(r.data[r.pos : r.pos+4])
+	r.pos += 4
+	r.cstring() // item name
+	if kind == "mime" {
+		contentType = r.cstring()
+	}
+	return id, kind, contentType
+}
+
+// boxReader reads big-endian fields of a box; short is set once a read runs
+// past the end.
+type boxReader struct {
+	data  []byte
+	pos   int
+	short bool
+}
+
+func (r *boxReader) uint(size int) uint64 {
+	if r.pos+size > len(r.data) {
+		r.short = true
+		return 0
+	}
+	var v uint64
+	for _, b := range r.data[r.pos : r.pos+size] {
+		v = v<<8 | uint64(b)
+	}
+	r.pos += size
+	return v
+}
+
+func (r *boxReader) cstring() string {
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		r.short = true
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	return s
+}
+
+// exifOrientation reads the orientation (1 to 8) from the first IFD of EXIF
+// data in TIFF layout; 1, upright, when it is missing.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		// A SHORT value sits in the first two bytes of the value field
+		if order.Uint16(tiff[entry:]) == exifOrientationTag && order.Uint16(tiff[entry+2:]) == 3 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+		}
+	}
+	return 1
+}
+
+// minimalExif is EXIF data in TIFF layout holding only the orientation.
+func minimalExif(orientation int) []byte {
+	return []byte{
+		'M', 'M', 0x00, 0x2A, 0, 0, 0, 8, // big endian, first IFD at 8
+		0, 1, // one entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, byte(orientation), 0, 0, // orientation, SHORT, 1 value
+		0, 0, 0, 0, // no next IFD
+	}
+}
@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+)
+
+// ImageOrientation reads the EXIF orientation (1 to 8) that StripImageMetadata
+// keeps in JPEG and PNG files; 1, upright, for other formats or without one.
+func ImageOrientation(data []byte, format string) int {
+	switch format {
+	case ImageJPEG:
+		for pos := 2; pos+4 <= len(data) && data[pos] == 0xFF; {
+			marker := data[pos+1]
+			if marker == 0xDA || marker == 0xD9 {
+				break
+			}
+			end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+			if end > len(data) {
+				break
+			}
+			if payload := data[pos+4 : end]; marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+				return exifOrientation(payload[6:])
+			}
+			pos = end
+		}
+	case ImagePNG:
+		for pos := 8; pos+12 <= len(data); {
+			end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:]))
+			if end > len(data) || end < pos+12 {
+				break
+			}
+			switch string(data[pos+4 : pos+8]) {
+			case "eXIf":
+				return exifOrientation(data[pos+8 : end-4])
+			case "IDAT":
+				return 1
+			}
+			pos = end
+		}
+	case ImageWebP:
+		for _, chunk := range riffChunks(data) {
+			if chunk.id == "EXIF" {
+				return exifOrientation(bytes.TrimPrefix(chunk.data, []byte("Exif\x00\x00")))
+			}
+		}
+	}
+	return 1
+}
+
+// ResizeImage scales src, turned upright by its EXIF orientation, to
+// width x height. With crop it fills the whole frame, cutting the excess
+// from the middle of the longer side; otherwise height is worked out from
+// width to keep the aspect ratio. Pixels are averaged over the area each
+// output pixel covers, which keeps downscaled photos sharp without
+// aliasing.
+func ResizeImage(src image.Image, orientation, width, height int, crop bool) *image.RGBA {
+	bounds := src.Bounds()
+	// Size of the picture as it is shown
+	shownW, shownH := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		shownW, shownH = shownH, shownW
+	}
+
+	area := image.Rect(0, 0, shownW, shownH)
+	if !crop || height <= 0 {
+		height = int(math.Round(float64(width) * float64(shownH) / float64(shownW)))
+		if height < 1 {
+			height = 1
+		}
+	} else if shownW*height > shownH*width {
+		cut := shownW - shownH*width/height
+		area = image.Rect(cut/2, 0, cut/2+shownW-cut, shownH)
+	} else {
+		cut := shownH - shownW*height/width
+		area = image.Rect(0, cut/2, shownW, cut/2+shownH-cut)
+	}
+
+	// Resize the matching area of the stored image, then turn the result
+	area = storedRect(area, orientation, bounds.Dx(), bounds.Dy()).Add(bounds.Min)
+	w, h := width, height
+	if orientation >= 5 {
+		w, h = h, w
+	}
+	return orient(resizeArea(src, area, w, h), orientation)
+}
+
+// storedRect maps a rectangle of the upright picture to the stored image of
+// size w x h.
+func storedRect(r image.Rectangle, orientation, w, h int) image.Rectangle {
+	point := func(x, y int) image.Point {
+		switch orientation {
+		case 2:
+			return image.Pt(w-x, y)
+		case 3:
+			return image.Pt(w-x, h-y)
+		case 4:
+			return image.Pt(x, h-y)
+		case 5:
+			return image.Pt(y, x)
+		case 6:
+			return image.Pt(y, h-x)
+		case 7:
+			return image.Pt(w-y, h-x)
+		case 8:
+			return image.Pt(w-y, x)
+		}
+		return image.Pt(x, y)
+	}
+	return image.Rectangle{Min: point(r.Min.X, r.Min.Y), Max: point(r.Max.X, r.Max.Y)}.Canon()
+}
+
+// orient turns a stored image upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			// Pixel centres, hence the -1s of storedRect's edges
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+type areaWeight struct {
+	index  int
+	weight float32
+}
+
+// areaWeights lists for each of dst output pixels the source pixels it
+// covers, with the share of each; the shares of one output pixel add up to 1.
+func areaWeights(src, dst int) [][]areaWeight {
+	scale := float64(src) / float64(dst)
+	weights := make([][]areaWeight, dst)
+	for j := range weights {
+		lo, hi := float64(j)*scale, float64(j+1)*scale
+		for i := int(lo); i < src && float64(i) < hi; i++ {
+			if overlap := math.Min(hi, float64(i+1)) - math.Max(lo, float64(i)); overlap > 0 {
+				weights[j] = append(weights[j], areaWeight{i, float32(overlap / scale)})
+			}
+		}
+	}
+	return weights
+}
+
+// resizeArea scales the part r of src to w x h. It works a row at a time,
+// so memory use does not grow with the size of src.
+func resizeArea(src image.Image, r image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	cols := areaWeights(r.Dx(), w)
+	rows := areaWeights(r.Dy(), h)
+
+	line := make([]float32, r.Dx()*4)
+	scaled := make([]float32, w*4)
+	acc := make([]float32, w*4)
+	cached := -1
+
+	for y, sources := range rows {
+		clear(acc)
+		for _, row := range sources {
+			if row.index != cached {
+				readRow(src, r, r.Min.Y+row.index, line)
+				clear(scaled)
+				for x, covered := range cols {
+					for _, col := range covered {
+						p := line[col.index*4 : col.index*4+4]
+						q := scaled[x*4 : x*4+4]
+						q[0] += p[0] * col.weight
+						q[1] += p[1] * col.weight
+						q[2] += p[2] * col.weight
+						q[3] += p[3] * col.weight
+					}
+				}
+				cached = row.index
+			}
+			for i, v := range scaled {
+				acc[i] += v * row.weight
+			}
+		}
+
+		out := dst.Pix[dst.PixOffset(0, y):]
+		for i, v := range acc {
+			out[i] = uint8(math.Min(255, math.Max(0, float64(v)+0.5)))
+		}
+	}
+	return dst
+}
+
+// readRow reads row y of src between r.Min.X and r.Max.X into line as
+// premultiplied RGBA, with fast paths for what the decoders return.
+func readRow(src image.Image, r image.Rectangle, y int, line []float32) {
+	switch img := src.(type) {
+	case *image.YCbCr:
+		for x := r.Min.X; x < r.Max.X; x++ {
+			yi, ci := img.YOffset(x, y), img.COffset(x, y)
+			red, green, blue := color.YCbCrToRGB(img.Y[yi], img.Cb[ci], img.Cr[ci])
+			i := (x - r.Min.X) * 4
+			line[i], line[i+1], line[i+2], line[i+3] = float32(red), float32(green), float32(blue), 255
+		}
+	case *image.RGBA:
+		pix := img.Pix[img.PixOffset(r.Min.X, y):]
+		for i := range line {
+			line[i] = float32(pix[i])
+		}
+	case *image.NRGBA:
+		pix := img.Pix[img.PixOffset(r.Min.X, y):]
+		for i := 0; i < len(line); i += 4 {
+			a := float32(pix[i+3]) / 255
+			line[i], line[i+1], line[i+2], line[i+3] = float32(pix[i])*a, float32(pix[i+1])*a, float32(pix[i+2])*a, float32(pix[i+3])
+		}
+	default:
+		for x := r.Min.X; x < r.Max.X; x++ {
+			red, green, blue, alpha := src.At(x, y).RGBA()
+			i := (x - r.Min.X) * 4
+			line[i], line[i+1], line[i+2], line[i+3] = float32(red>>8), float32(green>>8), float32(blue>>8), float32(alpha>>8)
+		}
+	}
+}
+
+// IsOpaque reports whether img has no transparent pixels, so it can be
+// stored as a JPEG.
+func IsOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
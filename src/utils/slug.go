@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify lowercases s and joins its words with hyphens. Letters, digits and
+// combining marks of any script are kept, so Bengali text stays readable
+// ("বাজেট ২০২৬" becomes "বাজেট-২০২৬").
+func Slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	for _, r := range NormalizeText(strings.ToLower(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		case unicode.IsMark(r) || r == zwnj || r == zwj:
+			// Only valid after a letter; a leading mark is dropped
+			if b.Len() > 0 && !pendingHyphen {
+				b.WriteRune(r)
+			}
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return b.String()
+}
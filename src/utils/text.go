@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	zwnj = '‌'
+	zwj  = '‍'
+)
+
+// NormalizeText returns s in Unicode NFC so that visually identical Bengali
+// text (for example য় typed as one code point or as য + ়) compares equal.
+func NormalizeText(s string) string {
+	return norm.NFC.String(s)
+}
+
+// SearchText prepares text for full-text matching: NFC plus removal of the
+// zero-width joiners, which only affect rendering but split words in
+// Postgres' parser. It must stay in line with the search_vector column.
+func SearchText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == zwnj || r == zwj {
+			return -1
+		}
+		return r
+	}, NormalizeText(s))
+}
+
+// Tokenize splits s into word, whitespace and punctuation tokens. Combining
+// marks (Bengali vowel signs, hasanta, chandrabindu, nukta) and zero-width
+// joiners always stay attached to the preceding character, so a grapheme
+// cluster is never split across tokens. Joining the tokens yields s again.
+func Tokenize(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	class := 0 // 0 none, 1 word, 2 space, 3 other
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range s {
+		if unicode.IsMark(r) || r == zwnj || r == zwj {
+			current.WriteRune(r)
+			continue
+		}
+
+		next := 3
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			next = 1
+		case unicode.IsSpace(r):
+			next = 2
+		}
+
+		// Punctuation is emitted one character at a time
+		if next != class || next == 3 {
+			flush()
+		}
+		class = next
+		current.WriteRune(r)
+	}
+	flush()
+
+	return tokens
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// StripTags removes HTML tags from s, leaving the text between them.
+func StripTags(s string) string {
+	return htmlTag.ReplaceAllString(s, " ")
+}
+
+// stopWords are frequent Bengali and English function words that say
+// nothing about what a text is about.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		এবং ও এই এ সেই সে তা তার তাদের তারা তিনি তাঁর তাঁরা আমি আমরা আমার আমাদের
+		আপনি আপনার তুমি যে যা যার যারা কে কি কী না নি নেই হয় হয়ে হয়েছে হয়েছিল হবে হলে
+		হতে ছিল ছিলেন আছে আছেন করে করা করেন করেছে করেছেন করতে করবে বলে বলেন জন্য
+		থেকে দিয়ে নিয়ে সঙ্গে সাথে পর পরে আগে মধ্যে উপর কাছে মতো এক একটি একজন কোনো
+		কিন্তু তবে আর বা অথবা যদি তাই এখন এর এটি এটা ওই শুধু আরও অনেক সব সবার
+		the a an and or of to in on for with at by from is are was were be been it
+		this that as not but
+	`) {
+		stopWords[NormalizeText(w)] = true
+	}
+}
+
+// Terms returns the lower-cased words of s worth comparing documents by:
+// search-normalised, without stop words and single letters.
+func Terms(s string) []string {
+	var terms []string
+	for _, token := range Tokenize(SearchText(s)) {
+		r, _ := utf8.DecodeRuneInString(token)
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		token = strings.ToLower(token)
+		if utf8.RuneCountInString(token) < 2 || stopWords[token] {
+			continue
+		}
+		terms = append(terms, token)
+	}
+	return terms
+}
+
+// Excerpt returns the text of an HTML fragment, with whitespace collapsed,
+// cut at a word boundary to at most max runes (an ellipsis marks the cut).
+func Excerpt(s string, max int) string {
+	text := strings.Join(strings.Fields(StripTags(s)), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-—।") + "…"
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "words and spaces", in: "ঢাকা  শহর", want: []string{"ঢাকা", "  ", "শহর"}},
+		{name: "punctuation one at a time", in: "কী?!", want: []string{"কী", "?", "!"}},
+		{name: "danda", in: "শেষ।", want: []string{"শেষ", "।"}},
+		{name: "vowel signs and chandrabindu", in: "চাঁদে গেছে", want: []string{"চাঁদে", " ", "গেছে"}},
+		{name: "conjunct with hasanta", in: "ক্ষমা", want: []string{"ক্ষমা"}},
+		{name: "zero-width non-joiner inside a word", in: "র‌যাব", want: []string{"র‌যাব"}},
+		// A vowel sign after a space has no letter to join; it still
+		// stays with what precedes it rather than standing alone
+		{name: "stray mark", in: "ক া", want: []string{"ক", " া"}},
+		{name: "latin and digits", in: "COVID-19 টিকা", want: []string{"COVID", "-", "19", " ", "টিকা"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.in)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, strings.Join(got, ""))
+		})
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	assert.Equal(t, "রযাব", SearchText("র‌যাব"))
+	assert.Equal(t, NormalizeText("নিয়ে"), SearchText("নিয়ে"))
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	hasanta = '্'
+	nukta   = '়'
+)
+
+var bnConsonants = map[rune]string{
+	'ক': "k", 'খ': "kh", 'গ': "g", 'ঘ': "gh", 'ঙ': "ng",
+	'চ': "ch", 'ছ': "chh", 'জ': "j", 'ঝ': "jh", 'ঞ': "n",
+	'ট': "t", 'ঠ': "th", 'ড': "d", 'ঢ': "dh", 'ণ': "n",
+	'ত': "t", 'থ': "th", 'দ': "d", 'ধ': "dh", 'ন': "n",
+	'প': "p", 'ফ': "f", 'ব': "b", 'ভ': "bh", 'ম': "m",
+	'য': "j", 'র': "r", 'ল': "l", 'শ': "sh", 'ষ': "sh",
+	'স': "s", 'হ': "h", 'ৎ': "t",
+}
+
+// ড়, ঢ় and য় decompose under NFC into a base consonant plus nukta
+var bnNukta = map[rune]string{'ড': "r", 'ঢ': "rh", 'য': "y"}
+
+var bnVowels = map[rune]string{
+	'অ': "o", 'আ': "a", 'ই': "i", 'ঈ': "i", 'উ': "u", 'ঊ': "u",
+	'ঋ': "ri", 'এ': "e", 'ঐ': "oi", 'ও': "o", 'ঔ': "ou",
+}
+
+var bnVowelSigns = map[rune]string{
+	'া': "a", 'ি': "i", 'ী': "i", 'ু': "u", 'ূ': "u",
+	'ৃ': "ri", 'ে': "e", 'ৈ': "oi", 'ো': "o", 'ৌ': "ou",
+}
+
+var bnSigns = map[rune]string{'ং': "ng", 'ঃ': "h", 'ঁ': ""}
+
+// Transliterate renders Bengali script in plain Latin letters the way readers
+// commonly romanise it ("বাংলাদেশ" → "bangladesh", "নির্বাচন" → "nirbachon").
+// The inherent vowel is written as "o" between consonants and dropped at the
+// end of a word. Text in other scripts is passed through unchanged.
+func Transliterate(s string) string {
+	runes := []rune(NormalizeText(s))
+	var b strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r >= '০' && r <= '৯' {
+			b.WriteRune('0' + (r - '০'))
+			continue
+		}
+		if v, ok := bnVowels[r]; ok {
+			b.WriteString(v)
+			continue
+		}
+		if v, ok := bnSigns[r]; ok {
+			b.WriteString(v)
+			continue
+		}
+		if v, ok := bnVowelSigns[r]; ok {
+			// A stray vowel sign without a consonant
+			b.WriteString(v)
+			continue
+		}
+
+		c, ok := bnConsonants[r]
+		if !ok {
+			if r != hasanta && r != nukta && r != zwnj && r != zwj {
+				b.WriteRune(r)
+			}
+			continue
+		}
+
+		// য-ফলা (as in ব্যবসা) is pronounced as a glide
+		if r == 'য' && i > 0 && runes[i-1] == hasanta {
+			c = "y"
+		}
+
+		if i+1 < len(runes) && runes[i+1] == nukta {
+			if v, ok := bnNukta[r]; ok {
+				c = v
+			}
+			i++
+		}
+		b.WriteString(c)
+
+		if i+1 >= len(runes) {
+			break
+		}
+		next := runes[i+1]
+		switch {
+		case next == hasanta:
+			// Conjunct: no vowel between the two consonants
+			i++
+		case bnVowelSigns[next] != "":
+			b.WriteString(bnVowelSigns[next])
+			i++
+		case r == 'ৎ':
+			// খণ্ড ত never carries the inherent vowel
+		case bnConsonants[next] != "", next == 'ং', next == 'ঃ':
+			b.WriteString("o")
+		}
+	}
+
+	return b.String()
+}
+
+// maxSlugLength keeps URLs short enough to share comfortably.
+const maxSlugLength = 80
+
+// ArticleSlug builds an ASCII-friendly URL slug from an article title,
+// cutting long titles at a word boundary.
+func ArticleSlug(title string) string {
+	slug := Slugify(Transliterate(title))
+
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if cut := strings.LastIndexByte(slug, '-'); cut > maxSlugLength/2 {
+			slug = slug[:cut]
+		}
+		slug = strings.TrimRightFunc(slug, func(r rune) bool { return r == '-' || r == unicode.ReplacementChar })
+	}
+
+	if slug == "" {
+		return "article"
+	}
+	return slug
+}
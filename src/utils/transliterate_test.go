@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"বাংলাদেশ", "bangladesh"},
+		{"নির্বাচন", "nirbachon"},
+		{"ঢাকা", "dhaka"},
+		{"সংসদ", "songsod"},
+		{"ক্ষমা", "kshoma"},
+		{"মৎস্য", "motsy"},
+		{"ব্যবসা", "byobosa"},
+		{"চাঁদ", "chad"},
+		// ড়, য় written as one code point or with a nukta
+		{"বা\u09dcি", "bari"},
+		{"বা\u09a1\u09bcি", "bari"},
+		{"দেয়াল", "deyal"},
+		{"২০২৪ সালের বাজেট", "2024 saler bajet"},
+		{"COVID-19 টিকা", "COVID-19 tika"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, Transliterate(tt.in))
+		})
+	}
+}
+
+func TestArticleSlug(t *testing.T) {
+	tests := []struct {
+		name, title, want string
+	}{
+		{"bengali title", "ঢাকায় ভারী বৃষ্টি, জনজীবন বিপর্যস্ত", "dhakay-bhari-brishti-jonojibon-biporyost"},
+		{"latin title", "Dhaka Stock Exchange: 5% Rise", "dhaka-stock-exchange-5-rise"},
+		{"nothing to keep", "!!!", "article"},
+		{
+			"long title cut at a word",
+			"প্রধানমন্ত্রী শেখ হাসিনা আজ জাতীয় সংসদে ২০২৪-২৫ অর্থবছরের বাজেট পেশ করেছেন এবং বিরোধী দল ওয়াকআউট করেছে",
+			"prodhanomontri-shekh-hasina-aj-jatiy-songsode-2024-25-orthobochhorer-bajet-pesh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slug := ArticleSlug(tt.title)
+			assert.Equal(t, tt.want, slug)
+			assert.LessOrEqual(t, len(slug), maxSlugLength)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Video formats accepted for upload, named by the extension they are
+// stored under.
+const (
+	VideoMP4  = "mp4"
+	VideoMOV  = "mov"
+	VideoWebM = "webm"
+)
+
+// VideoMIME maps a video format to its content type.
+var VideoMIME = map[string]string{
+	VideoMP4:  "video/mp4",
+	VideoMOV:  "video/quicktime",
+	VideoWebM: "video/webm",
+}
+
+// Brands of the ftyp box that mark a still image, not a video
+var imageBrands = map[string]bool{"avif": true, "avis": true, "heic": true, "heix": true, "mif1": true, "msf1": true}
+
+// SniffVideo names the format of a video from its first bytes, or returns
+// "" when it is none of the accepted formats. As with SniffImage, the file
+// name and the declared type are never trusted.
+func SniffVideo(data []byte) string {
+	if len(data) < 12 {
+		return ""
+	}
+	if bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		// A Matroska container; only its WebM profile plays in browsers
+		if bytes.Contains(data[:min(len(data), 64)], []byte("webm")) {
+			return VideoWebM
+		}
+		return ""
+	}
+
+	switch string(data[4:8]) {
+	case "ftyp":
+		size := int(binary.BigEndian.Uint32(data))
+		if size < 12 || size > len(data) {
+			size = min(len(data), 64)
+		}
+		// Major brand, minor version, then compatible brands
+		for i := 8; i+4 <= size; i += 4 {
+			if i == 12 {
+				continue
+			}
+			if imageBrands[string(data[i:i+4])] {
+				return ""
+			}
+		}
+		if string(data[8:12]) == "qt  " {
+			return VideoMOV
+		}
+		return VideoMP4
+	case "moov", "mdat", "wide", "free":
+		// QuickTime files from before the ftyp box
+		return VideoMOV
+	}
+	return ""
+}
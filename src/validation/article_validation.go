@@ -0,0 +1,70 @@
+package validation
+
+import "time"
+
+type ArticleTransition struct {
+	ID     int    `json:"id" validate:"required,number"`
+	Action string `json:"action" validate:"required,oneof=submit approve reject publish archive"`
+	Note   string `json:"note" validate:"omitempty,max=1000"`
+	// Optional embargo for the publish action; a future time schedules the article
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	ExpireAt  *time.Time `json:"expire_at,omitempty"`
+}
+
+type RestoreRevision struct {
+	ID       int `json:"id" validate:"required,number"`
+	Revision int `json:"revision" validate:"required,number"`
+	// The version the caller is looking at, or If-Match; 0 after If-Match: *
+	Version int `json:"version" validate:"number,min=0"`
+}
+
+type QuerySearch struct {
+	Q        string `validate:"required,max=200"`
+	Page     int    `validate:"omitempty,number,min=1"`
+	Limit    int    `validate:"omitempty,number,max=50"`
+	Category string `validate:"omitempty,max=150"`
+}
+
+type QueryArticle struct {
+	Page     int    `validate:"omitempty,number,min=1"`
+	Limit    int    `validate:"omitempty,number,max=100"`
+	Search   string `validate:"omitempty,max=200"`
+	Category string `validate:"omitempty,max=150"`
+	// Opaque keyset cursor from a previous page's next_cursor; overrides Page
+	Cursor string `validate:"omitempty,max=100"`
+}
+
+type QueryMostRead struct {
+	Window   string `validate:"omitempty,oneof=hour day week"`
+	Category string `validate:"omitempty,max=150"`
+	Limit    int    `validate:"omitempty,number,max=50"`
+}
+
+type ShareArticle struct {
+	ID      int    `json:"id" validate:"required,number"`
+	Network string `json:"network" validate:"omitempty,alphanum,max=30" example:"facebook"`
+}
+
+type QueryTrending struct {
+	Category string `validate:"omitempty,max=150"`
+	Limit    int    `validate:"omitempty,number,max=50"`
+}
+
+type QueryTrash struct {
+	Page   int    `validate:"omitempty,number,min=1"`
+	Limit  int    `validate:"omitempty,number,max=100"`
+	Search string `validate:"omitempty,max=200"`
+}
+
+// BulkArticles applies one operation to many articles. Category is needed to
+// move, Action (and for reject, Note) to transition and Author to
+// assign_author.
+type BulkArticles struct {
+	IDs       []int  `json:"ids" validate:"required,min=1,max=200,dive,min=1"`
+	Operation string `json:"operation" validate:"required,oneof=feature unfeature move transition delete assign_author" example:"move"`
+	Category  string `json:"category" validate:"omitempty,max=150" example:"economy"`
+	Action    string `json:"action" validate:"omitempty,oneof=submit approve reject publish archive"`
+	Note      string `json:"note" validate:"omitempty,max=1000"`
+	Author    string `json:"author" validate:"omitempty,max=255"`
+	DryRun    bool   `json:"dry_run"`
+}
@@ -0,0 +1,17 @@
+package validation
+
+import "time"
+
+type CreateBreakingNews struct {
+	Headline string     `json:"headline" validate:"required,max=300" example:"রাজধানীতে ভূমিকম্প অনুভূত"`
+	Link     *string    `json:"link,omitempty" validate:"omitempty,max=500" example:"/news/rajdhanite-bhumikampa"`
+	Priority int        `json:"priority" validate:"min=0,max=100" example:"10"`
+	StartAt  *time.Time `json:"start_at,omitempty" example:"2026-10-17T09:00:00+06:00"`
+	EndAt    *time.Time `json:"end_at,omitempty" example:"2026-10-17T12:00:00+06:00"`
+}
+
+type QueryBreakingNews struct {
+	Page   int    `validate:"omitempty,number,min=1"`
+	Limit  int    `validate:"omitempty,number,min=1,max=100"`
+	Status string `validate:"omitempty,oneof=scheduled live expired"`
+}
@@ -0,0 +1,19 @@
+package validation
+
+type CreateCategory struct {
+	Slug      string `json:"slug" validate:"omitempty,max=100" example:"economy"`
+	NameBn    string `json:"name_bn" validate:"required,max=150" example:"অর্থনীতি"`
+	NameEn    string `json:"name_en" validate:"omitempty,max=150" example:"Economy"`
+	ParentID  *int   `json:"parent_id,omitempty" validate:"omitempty,number"`
+	SortOrder int    `json:"sort_order" validate:"omitempty,number"`
+	IsActive  *bool  `json:"is_active,omitempty"`
+}
+
+type UpdateCategory struct {
+	Slug      *string `json:"slug,omitempty" validate:"omitempty,min=1,max=100"`
+	NameBn    *string `json:"name_bn,omitempty" validate:"omitempty,min=1,max=150"`
+	NameEn    *string `json:"name_en,omitempty" validate:"omitempty,max=150"`
+	ParentID  *int    `json:"parent_id,omitempty" validate:"omitempty,number"`
+	SortOrder *int    `json:"sort_order,omitempty" validate:"omitempty,number"`
+	IsActive  *bool   `json:"is_active,omitempty"`
+}
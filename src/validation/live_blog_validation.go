@@ -0,0 +1,33 @@
+package validation
+
+import "time"
+
+type CreateLiveBlog struct {
+	ArticleID int    `json:"article_id" validate:"required,min=1" example:"42"`
+	Title     string `json:"title" validate:"omitempty,max=300" example:"বাজেট ২০২৬-২৭: সরাসরি"`
+}
+
+type UpdateLiveBlog struct {
+	Title  *string `json:"title,omitempty" validate:"omitempty,max=300"`
+	Status *string `json:"status,omitempty" validate:"omitempty,oneof=live ended" example:"ended"`
+}
+
+type CreateLiveBlogEntry struct {
+	Body   string  `json:"body" validate:"required,max=20000" example:"অর্থমন্ত্রী বাজেট বক্তৃতা শুরু করেছেন।"`
+	Image  *string `json:"image,omitempty" validate:"omitempty,max=500" example:"/uploads/budget.jpg"`
+	Author string  `json:"author" validate:"omitempty,max=255" example:"নিজস্ব প্রতিবেদক"`
+	Pinned bool    `json:"pinned" example:"false"`
+}
+
+type UpdateLiveBlogEntry struct {
+	Body   *string `json:"body,omitempty" validate:"omitempty,min=1,max=20000"`
+	Image  *string `json:"image,omitempty" validate:"omitempty,max=500"`
+	Author *string `json:"author,omitempty" validate:"omitempty,min=1,max=255"`
+	Pinned *bool   `json:"pinned,omitempty"`
+}
+
+type QueryLiveBlogEntries struct {
+	Page  int        `validate:"omitempty,number,min=1"`
+	Limit int        `validate:"omitempty,number,min=1,max=100"`
+	Since *time.Time `validate:"-"`
+}
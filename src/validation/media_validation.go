@@ -0,0 +1,33 @@
+package validation
+
+// MediaDetails describes a photo. It is sent with an upload, where only the
+// blanks of an already stored picture are filled in, and to edit one.
+type MediaDetails struct {
+	Alt     *string `json:"alt,omitempty" validate:"omitempty,max=500" example:"সংসদ ভবনের সামনে মানববন্ধন"`
+	Caption *string `json:"caption,omitempty" validate:"omitempty,max=2000" example:"বৃহস্পতিবার সকালে জাতীয় সংসদ ভবনের সামনে শিক্ষার্থীদের মানববন্ধন।"`
+	Credit  *string `json:"credit,omitempty" validate:"omitempty,max=200" example:"ছবি: নিজস্ব আলোকচিত্রী"`
+	License *string `json:"license,omitempty" validate:"omitempty,max=100" example:"সর্বস্বত্ব সংরক্ষিত"`
+}
+
+type QueryMedia struct {
+	Page       int    `validate:"omitempty,number,min=1"`
+	Limit      int    `validate:"omitempty,number,min=1,max=100"`
+	Search     string `validate:"omitempty,max=200"`
+	Type       string `validate:"omitempty,oneof=image/jpeg image/png image/gif image/webp image/avif video/mp4 video/quicktime video/webm"`
+	Credit     string `validate:"omitempty,max=200"`
+	License    string `validate:"omitempty,max=100"`
+	UploadedBy int    `validate:"omitempty,min=1"`
+	Status     string `validate:"omitempty,oneof=pending processing ready failed skipped"`
+	// Only media nothing uses
+	Unused bool `validate:"-"`
+}
+
+// AttachUpload puts a finished resumable upload on an article: a photo as its
+// main image or in its body, a video in its body. Placement defaults to
+// image for photos. A non-zero Version must match the article's, as on
+// update.
+type AttachUpload struct {
+	ArticleID int    `json:"article_id" validate:"required,min=1" example:"42"`
+	Placement string `json:"placement" validate:"omitempty,oneof=image content" example:"content"`
+	Version   int    `json:"version" validate:"omitempty,min=1" example:"3"`
+}
@@ -0,0 +1,16 @@
+package validation
+
+type QueryTag struct {
+	Page   int    `validate:"omitempty,number,min=1"`
+	Limit  int    `validate:"omitempty,number,max=100"`
+	Search string `validate:"omitempty,max=150"`
+}
+
+type RenameTag struct {
+	Name string `json:"name" validate:"required,max=150" example:"বাজেট ২০২৬"`
+	Slug string `json:"slug,omitempty" validate:"omitempty,max=150"`
+}
+
+type MergeTag struct {
+	Into int `json:"into" validate:"required,number"`
+}
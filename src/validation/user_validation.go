@@ -9,6 +9,11 @@ type CreateUser struct {
 	Status    string `json:"status,omitempty" validate:"omitempty,oneof=active inactive banned" example:"active"`
 }
 
+// UpdateRole names one of config.Roles
+type UpdateRole struct {
+	Role string `json:"role" validate:"required,max=20" example:"editor"`
+}
+
 type UpdateUser struct {
 	Name      string `json:"name,omitempty" validate:"omitempty,max=100" example:"John Doe"`
 	Email     string `json:"email,omitempty" validate:"omitempty,email,max=150" example:"john@example.com"`